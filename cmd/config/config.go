@@ -1,12 +1,38 @@
 package config
 
 import (
+	"context"
+	"errors"
+	"expvar"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"app/internal/backend"
+	"app/internal/claims"
+	"app/internal/memory"
+	"app/internal/notify"
+	"app/internal/password"
+	"app/internal/petition"
+	"app/internal/revocation"
+	"app/internal/server"
+	"app/internal/service"
+	"app/internal/signature"
+	"app/internal/sqlite"
+	"app/internal/transport"
+	"app/internal/validation"
 
 	"github.com/joho/godotenv"
 )
 
+// ErrConfig is returned for inconsistent settings.
+var ErrConfig = errors.New("invalid configuration")
+
 func VerifyIsDockerRun() (check bool) {
 	isDocker := os.Getenv("DOCKER")
 
@@ -20,3 +46,491 @@ func LoadEnv(path string) (err error) {
 
 	return nil
 }
+
+// NewServer reads PORT, METRICS_PORT, MAX_BODY_BYTES, the RFC 3339 dates of
+// API_V1_DEPRECATION and API_V1_SUNSET, the AUTH_* token sources, the TLS
+// and security headers settings and the CORS_*, SESSION_* and CSRF_* ones.
+func NewServer() (cfg *server.Config, err error) {
+	cfg = &server.Config{
+		Port:         os.Getenv("PORT"),
+		TokenHeader:  os.Getenv("AUTH_HEADER"),
+		TokenCookie:  os.Getenv("AUTH_COOKIE"),
+		TokenQuery:   os.Getenv("AUTH_QUERY_PARAM"),
+		TLSCertFile:  os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:   os.Getenv("TLS_KEY_FILE"),
+		RedirectPort: os.Getenv("HTTP_REDIRECT_PORT"),
+		MetricsPort:  os.Getenv("METRICS_PORT"),
+		Security: server.SecurityHeaders{
+			HSTSIncludeSubdomains: os.Getenv("HSTS_INCLUDE_SUBDOMAINS") == "true",
+			HSTSPreload:           os.Getenv("HSTS_PRELOAD") == "true",
+			FrameOptions:          os.Getenv("FRAME_OPTIONS"),
+			ReferrerPolicy:        os.Getenv("REFERRER_POLICY"),
+		},
+	}
+
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("%w: TLS_CERT_FILE and TLS_KEY_FILE go together", ErrConfig)
+	}
+
+	if os.Getenv("HSTS_MAX_AGE") != "" {
+		if cfg.Security.HSTSMaxAge, err = time.ParseDuration(os.Getenv("HSTS_MAX_AGE")); err != nil {
+			return nil, fmt.Errorf("invalid HSTS_MAX_AGE: %w", err)
+		}
+	}
+
+	if os.Getenv("TLS_RELOAD_INTERVAL") != "" {
+		if cfg.TLSReloadInterval, err = time.ParseDuration(os.Getenv("TLS_RELOAD_INTERVAL")); err != nil {
+			return nil, fmt.Errorf("invalid TLS_RELOAD_INTERVAL: %w", err)
+		}
+	}
+
+	if os.Getenv("MAX_BODY_BYTES") != "" {
+		if cfg.MaxBodyBytes, err = strconv.ParseInt(os.Getenv("MAX_BODY_BYTES"), 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid MAX_BODY_BYTES: %w", err)
+		}
+	}
+
+	for key, date := range map[string]*time.Time{
+		"API_V1_DEPRECATION": &cfg.Deprecation,
+		"API_V1_SUNSET":      &cfg.Sunset,
+	} {
+		if os.Getenv(key) == "" {
+			continue
+		}
+
+		if *date, err = time.Parse(time.RFC3339, os.Getenv(key)); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	if cfg.CORS, err = newCORS(); err != nil {
+		return nil, err
+	}
+
+	if os.Getenv("SESSION_COOKIES") == "true" {
+		if cfg.Session, err = newBrowserSession(cfg.TokenCookie); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// newCORS reads the comma-separated CORS_ALLOWED_* and CORS_EXPOSED_HEADERS
+// lists, CORS_ALLOW_CREDENTIALS and CORS_MAX_AGE.
+func newCORS() (cors server.CORS, err error) {
+	cors = server.CORS{
+		AllowedOrigins:   listEnv("CORS_ALLOWED_ORIGINS"),
+		AllowedMethods:   listEnv("CORS_ALLOWED_METHODS"),
+		AllowedHeaders:   listEnv("CORS_ALLOWED_HEADERS"),
+		ExposedHeaders:   listEnv("CORS_EXPOSED_HEADERS"),
+		AllowCredentials: os.Getenv("CORS_ALLOW_CREDENTIALS") == "true",
+	}
+
+	for _, origin := range cors.AllowedOrigins {
+		if origin == "*" && cors.AllowCredentials {
+			return cors, fmt.Errorf("%w: CORS_ALLOW_CREDENTIALS needs explicit CORS_ALLOWED_ORIGINS", ErrConfig)
+		}
+	}
+
+	if os.Getenv("CORS_MAX_AGE") != "" {
+		if cors.MaxAge, err = time.ParseDuration(os.Getenv("CORS_MAX_AGE")); err != nil {
+			return cors, fmt.Errorf("invalid CORS_MAX_AGE: %w", err)
+		}
+	}
+
+	return cors, nil
+}
+
+// newBrowserSession reads the SESSION_COOKIE_* and CSRF_* variables, the
+// session cookie is named by AUTH_COOKIE.
+func newBrowserSession(cookie string) (session transport.BrowserSession, err error) {
+	if cookie == "" {
+		return session, fmt.Errorf("%w: SESSION_COOKIES needs AUTH_COOKIE", ErrConfig)
+	}
+
+	session = transport.BrowserSession{
+		Cookie:     cookie,
+		Domain:     os.Getenv("SESSION_COOKIE_DOMAIN"),
+		Path:       os.Getenv("SESSION_COOKIE_PATH"),
+		Insecure:   os.Getenv("SESSION_COOKIE_INSECURE") == "true",
+		CSRF:       os.Getenv("CSRF_MODE"),
+		CSRFCookie: os.Getenv("CSRF_COOKIE"),
+		CSRFHeader: os.Getenv("CSRF_HEADER"),
+		CSRFKey:    []byte(os.Getenv("CSRF_KEY")),
+	}
+
+	switch strings.ToLower(os.Getenv("SESSION_COOKIE_SAMESITE")) {
+	case "", "lax":
+		session.SameSite = http.SameSiteLaxMode
+	case "strict":
+		session.SameSite = http.SameSiteStrictMode
+	case "none":
+		session.SameSite = http.SameSiteNoneMode
+	default:
+		return session, fmt.Errorf("%w: invalid SESSION_COOKIE_SAMESITE", ErrConfig)
+	}
+
+	if os.Getenv("SESSION_COOKIE_MAX_AGE") != "" {
+		if session.MaxAge, err = time.ParseDuration(os.Getenv("SESSION_COOKIE_MAX_AGE")); err != nil {
+			return session, fmt.Errorf("invalid SESSION_COOKIE_MAX_AGE: %w", err)
+		}
+	}
+
+	switch session.CSRF {
+	case "", transport.CSRFDoubleSubmit:
+	case transport.CSRFSynchronizer:
+		if len(session.CSRFKey) == 0 {
+			return session, fmt.Errorf("%w: CSRF_MODE=synchronizer needs CSRF_KEY", ErrConfig)
+		}
+	default:
+		return session, fmt.Errorf("%w: invalid CSRF_MODE", ErrConfig)
+	}
+
+	return session, nil
+}
+
+// NewInfoServices reads the backends configuration from the environment.
+func NewInfoServices() (*service.InfoServices, error) {
+	policy, err := newPasswordPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	validator, err := NewValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := newTokenVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	notifier, err := newNotifier()
+	if err != nil {
+		return nil, err
+	}
+
+	admins, err := idsEnv("ADMINS")
+	if err != nil {
+		return nil, err
+	}
+
+	return &service.InfoServices{
+		DBURL:     os.Getenv("DB_URL"),
+		TokenURL:  os.Getenv("TOKEN_URL"),
+		DBHost:    os.Getenv("DB_HOST"),
+		DBPort:    os.Getenv("DB_PORT"),
+		TokenHost: os.Getenv("TOKEN_HOST"),
+		TokenPort: os.Getenv("TOKEN_PORT"),
+		Secret:    os.Getenv("SECRET"),
+		Admins:    admins,
+		PasswordParams: password.Params{
+			Time:    uint32(uintEnv("ARGON2_TIME", 32)),
+			Memory:  uint32(uintEnv("ARGON2_MEMORY", 32)),
+			Threads: uint8(uintEnv("ARGON2_THREADS", 8)),
+		},
+		PasswordPepper: os.Getenv("PASSWORD_PEPPER"),
+		PasswordPolicy: policy,
+		Validator:      validator,
+		TokenVerifier:  verifier,
+		Sessions:       memory.NewSessions(int(uintEnv("SESSIONS_MAX", 31))),
+		Logins:         memory.NewLogins(int(uintEnv("LOGIN_HISTORY_MAX", 31))),
+		Notifier:       notifier,
+	}, nil
+}
+
+// newNotifier returns the notifier of sign-ins from new devices named by
+// NEW_DEVICE_NOTIFIER: "log", "webhook" posting to NEW_DEVICE_WEBHOOK_URL,
+// or nil when it is unset.
+func newNotifier() (notifier notify.Notifier, err error) {
+	switch kind := os.Getenv("NEW_DEVICE_NOTIFIER"); kind {
+	case "", "none":
+		return nil, nil //nolint:nilnil
+	case "log":
+		return notify.NewLog(nil), nil
+	case "webhook":
+		url := os.Getenv("NEW_DEVICE_WEBHOOK_URL")
+		if url == "" {
+			return nil, fmt.Errorf("%w: NEW_DEVICE_NOTIFIER=webhook needs NEW_DEVICE_WEBHOOK_URL", ErrConfig)
+		}
+
+		// The service bounds each notice, see its notifyTimeout.
+		return notify.NewWebhook(&http.Client{}, url), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown NEW_DEVICE_NOTIFIER %q", ErrConfig, kind)
+	}
+}
+
+// newTokenVerifier returns the verifier of TOKEN_VERIFICATION=local, nil
+// for remote, the default. TOKEN_LEEWAY is the clock difference tolerated
+// and TOKEN_REQUIRE_EXPIRY rejects tokens without exp.
+func newTokenVerifier() (verifier *claims.Verifier, err error) {
+	switch mode := os.Getenv("TOKEN_VERIFICATION"); mode {
+	case "", "remote":
+		return nil, nil //nolint:nilnil
+	case "local":
+	default:
+		return nil, fmt.Errorf("%w: unknown TOKEN_VERIFICATION %q", ErrConfig, mode)
+	}
+
+	cfg := claims.VerifierConfig{RequireExpiry: os.Getenv("TOKEN_REQUIRE_EXPIRY") == "true"}
+
+	if os.Getenv("TOKEN_LEEWAY") != "" {
+		if cfg.Leeway, err = time.ParseDuration(os.Getenv("TOKEN_LEEWAY")); err != nil {
+			return nil, fmt.Errorf("invalid TOKEN_LEEWAY: %w", err)
+		}
+	}
+
+	if verifier, err = claims.NewVerifier([]byte(os.Getenv("SECRET")), cfg, nil); err != nil {
+		return nil, fmt.Errorf("%w: TOKEN_VERIFICATION=local needs a SECRET", ErrConfig)
+	}
+
+	return verifier, nil
+}
+
+// NewService returns the service on the backends of BACKEND_MODE: "http",
+// the default, calls the storage and token services with NewBackendClient,
+// "embedded" keeps users and at most EMBEDDED_MAX_TOKENS tokens in the
+// process, signed with SECRET. In either mode, users are kept in the SQLite
+// file SQLITE_FILE when it is set, closed by closeFn. The users with the IDs
+// of ADMINS are made admins.
+func NewService(is *service.InfoServices) (svc service.Service, closeFn func() error, err error) {
+	var (
+		storage backend.StorageClient
+		token   backend.TokenClient
+	)
+
+	switch mode := os.Getenv("BACKEND_MODE"); mode {
+	case "", "http":
+		client, err := NewBackendClient()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		dbURL, tokenURL := is.BaseURLs()
+		storage, token = petition.NewStorageClient(client, dbURL), petition.NewTokenClient(client, tokenURL)
+	case "embedded":
+		if is.Secret == "" {
+			return nil, nil, fmt.Errorf("%w: BACKEND_MODE=embedded needs a SECRET", ErrConfig)
+		}
+
+		storage, token = memory.NewStorage(), memory.NewTokens(nil, int(uintEnv("EMBEDDED_MAX_TOKENS", 31)))
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown BACKEND_MODE %q", ErrConfig, mode)
+	}
+
+	cacheCfg, err := newRevocationConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closeFn = func() error { return nil }
+
+	if file := os.Getenv("SQLITE_FILE"); file != "" {
+		sqliteStorage, err := sqlite.Open(context.Background(), file)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		storage, closeFn = sqliteStorage, sqliteStorage.Close
+	}
+
+	if cacheCfg.TTL > 0 {
+		cache := revocation.NewCache(token, cacheCfg, nil)
+		// Publish panics on a name taken, by a service made before.
+		if expvar.Get("token_cache") == nil {
+			expvar.Publish("token_cache", expvar.Func(func() any { return cache.Stats() }))
+		}
+
+		token = cache
+	}
+
+	backends := service.NewServiceWithBackends(storage, token, is)
+	if err = backends.PromoteAdmins(is.Admins); err != nil {
+		_ = closeFn()
+
+		return nil, nil, err
+	}
+
+	return backends, closeFn, nil
+}
+
+// newRevocationConfig reads TOKEN_CACHE_TTL, no cache when unset,
+// TOKEN_CACHE_NEGATIVE_TTL and TOKEN_CACHE_MAX_ENTRIES.
+func newRevocationConfig() (cfg revocation.Config, err error) {
+	for key, duration := range map[string]*time.Duration{
+		"TOKEN_CACHE_TTL":          &cfg.TTL,
+		"TOKEN_CACHE_NEGATIVE_TTL": &cfg.NegativeTTL,
+	} {
+		if os.Getenv(key) == "" {
+			continue
+		}
+
+		if *duration, err = time.ParseDuration(os.Getenv(key)); err != nil {
+			return revocation.Config{}, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	cfg.MaxEntries = int(uintEnv("TOKEN_CACHE_MAX_ENTRIES", 31))
+
+	return cfg, nil
+}
+
+// NewBackendClient returns the client of the storage and token services,
+// with the CA bundle, client certificate and key of BACKEND_CA_FILE,
+// BACKEND_CERT_FILE and BACKEND_KEY_FILE checked for changes every
+// BACKEND_TLS_RELOAD_INTERVAL, and the server names of DB_SERVER_NAME and
+// TOKEN_SERVER_NAME for the hosts of DB_URL and TOKEN_URL. With
+// BACKEND_SIGNING_KEY, every request is signed with it.
+func NewBackendClient() (client petition.HTTPClient, err error) {
+	cfg := petition.TLSConfig{
+		CAFile:      os.Getenv("BACKEND_CA_FILE"),
+		CertFile:    os.Getenv("BACKEND_CERT_FILE"),
+		KeyFile:     os.Getenv("BACKEND_KEY_FILE"),
+		ServerNames: make(map[string]string),
+	}
+
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, fmt.Errorf("%w: BACKEND_CERT_FILE and BACKEND_KEY_FILE go together", ErrConfig)
+	}
+
+	for urlKey, nameKey := range map[string]string{
+		"DB_URL":    "DB_SERVER_NAME",
+		"TOKEN_URL": "TOKEN_SERVER_NAME",
+	} {
+		if os.Getenv(nameKey) == "" {
+			continue
+		}
+
+		backend, err := url.Parse(os.Getenv(urlKey))
+		if err != nil || backend.Scheme != "https" {
+			return nil, fmt.Errorf("%w: %s needs an https:// %s", ErrConfig, nameKey, urlKey)
+		}
+
+		host := backend.Host
+		if backend.Port() == "" {
+			host = net.JoinHostPort(backend.Hostname(), "443")
+		}
+
+		cfg.ServerNames[host] = os.Getenv(nameKey)
+	}
+
+	if os.Getenv("BACKEND_TLS_RELOAD_INTERVAL") != "" {
+		if cfg.ReloadInterval, err = time.ParseDuration(os.Getenv("BACKEND_TLS_RELOAD_INTERVAL")); err != nil {
+			return nil, fmt.Errorf("invalid BACKEND_TLS_RELOAD_INTERVAL: %w", err)
+		}
+	}
+
+	if client, err = petition.NewClient(cfg); err != nil {
+		return nil, err
+	}
+
+	if os.Getenv("BACKEND_SIGNING_KEY") == "" {
+		return client, nil
+	}
+
+	signer, err := signature.NewSigner([]byte(os.Getenv("BACKEND_SIGNING_KEY")), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BACKEND_SIGNING_KEY: %w", err)
+	}
+
+	return petition.NewSigningClient(client, signer), nil
+}
+
+// NewValidator adds the names in RESERVED_USERNAMES_FILE and the domains in
+// DISPOSABLE_DOMAINS_FILE, one per line, to the default lists.
+func NewValidator() (*validation.Validator, error) {
+	reserved := validation.DefaultReservedUsernames()
+	disposable := validation.DefaultDisposableDomains()
+
+	for key, list := range map[string]*[]string{
+		"RESERVED_USERNAMES_FILE": &reserved,
+		"DISPOSABLE_DOMAINS_FILE": &disposable,
+	} {
+		if os.Getenv(key) == "" {
+			continue
+		}
+
+		values, err := validation.ReadList(os.Getenv(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+
+		*list = append(*list, values...)
+	}
+
+	return validation.NewValidator(reserved, disposable), nil
+}
+
+// newPasswordPolicy starts from password.DefaultPolicy, the PASSWORD_*
+// variables override its fields and BREACHED_PASSWORDS_DIR enables the
+// breached-password check.
+func newPasswordPolicy() (policy password.Policy, err error) {
+	policy = password.DefaultPolicy()
+
+	for key, value := range map[string]*int{
+		"PASSWORD_MIN_LENGTH":  &policy.MinLength,
+		"PASSWORD_MAX_LENGTH":  &policy.MaxLength,
+		"PASSWORD_MIN_CLASSES": &policy.MinClasses,
+	} {
+		if os.Getenv(key) == "" {
+			continue
+		}
+
+		if *value, err = strconv.Atoi(os.Getenv(key)); err != nil {
+			return password.Policy{}, fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	if os.Getenv("PASSWORD_MIN_ENTROPY") != "" {
+		if policy.MinEntropy, err = strconv.ParseFloat(os.Getenv("PASSWORD_MIN_ENTROPY"), 64); err != nil {
+			return password.Policy{}, fmt.Errorf("invalid PASSWORD_MIN_ENTROPY: %w", err)
+		}
+	}
+
+	if dir := os.Getenv("BREACHED_PASSWORDS_DIR"); dir != "" {
+		if policy.Breached, err = password.NewBreachedList(dir); err != nil {
+			return password.Policy{}, err
+		}
+	}
+
+	return policy, nil
+}
+
+// listEnv splits a comma-separated variable, nil when it is unset.
+func listEnv(key string) (list []string) {
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+// idsEnv reads a comma-separated list of user IDs from the environment.
+func idsEnv(key string) (ids []int, err error) {
+	for _, item := range listEnv(key) {
+		id, err := strconv.Atoi(item)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%w: %s must list user IDs, not %q", ErrConfig, key, item)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// uintEnv reads an unsigned integer of the given bit size from the
+// environment, 0 when it is unset or invalid so the default applies.
+func uintEnv(key string, bitSize int) uint64 {
+	value, err := strconv.ParseUint(os.Getenv(key), 10, bitSize)
+	if err != nil {
+		return 0
+	}
+
+	return value
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"app/cmd/config"
+	"app/internal/entity"
+	"app/internal/importer"
+	"app/internal/service"
+)
+
+func main() {
+	log.SetFlags(log.Lshortfile)
+
+	var options entity.ImportOptions
+
+	file := flag.String("file", "", "CSV or NDJSON file with username, password and email of each user")
+	format := flag.String("format", "", "csv or ndjson, guessed from the file extension when empty")
+	flag.BoolVar(&options.DryRun, "dry-run", false, "validate the rows without creating users")
+	flag.IntVar(&options.Concurrency, "concurrency", service.DefaultImportConcurrency, "users created at once")
+	flag.Parse()
+
+	if !config.VerifyIsDockerRun() {
+		if err := config.LoadEnv("./config/.env"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	rows, err := readRows(*file, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// Embedded users only live in this process, they would be lost on exit.
+	if os.Getenv("BACKEND_MODE") == "embedded" && os.Getenv("SQLITE_FILE") == "" {
+		log.Fatal(fmt.Errorf("%w: importing with BACKEND_MODE=embedded needs a SQLITE_FILE", config.ErrConfig))
+	}
+
+	infServ, err := config.NewInfoServices()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	svc, closeService, err := config.NewService(infServ)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := svc.ImportUsers(ctx, rows, options)
+
+	// The storage is closed before exiting, log.Fatal skips deferred calls.
+	if closeErr := closeService(); closeErr != nil {
+		log.Println(closeErr)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "\t")
+
+	if err = encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func readRows(file, format string) (rows []entity.ImportRow, err error) {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("error to open import: %w", err)
+	}
+	defer f.Close()
+
+	if rows, err = importer.Parse(format, f); err != nil {
+		return nil, fmt.Errorf("error to read import: %w", err)
+	}
+
+	return rows, nil
+}
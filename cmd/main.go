@@ -2,17 +2,10 @@ package main
 
 import (
 	"log"
-	"net/http"
-	"os"
 
 	"app/cmd/config"
-	"app/internal/endpoint"
-	"app/internal/entity"
+	"app/internal/server"
 	"app/internal/service"
-	"app/internal/transport"
-
-	httptransport "github.com/go-kit/kit/transport/http"
-	"github.com/gorilla/mux"
 )
 
 func main() {
@@ -24,70 +17,32 @@ func main() {
 		}
 	}
 
-	infServ := service.InfoServices{
-		DBHost:    os.Getenv("DB_HOST"),
-		DBPort:    os.Getenv("DB_PORT"),
-		TokenHost: os.Getenv("TOKEN_HOST"),
-		TokenPort: os.Getenv("TOKEN_PORT"),
-		Secret:    os.Getenv("SECRET"),
+	cfg, err := config.NewServer()
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	runServer(
-		os.Getenv("PORT"),
-		&infServ,
-	)
-}
+	infServ, err := config.NewInfoServices()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-func runServer(port string, infServ *service.InfoServices) {
-	svc := service.NewService(
-		&http.Client{},
+	runServer(
+		cfg,
 		infServ,
 	)
+}
 
-	getSignUpHandler := httptransport.NewServer(
-		endpoint.MakeSignUpEndpoint(svc),
-		transport.DecodeRequestWithBody(entity.UsernamePasswordEmailRequest{}),
-		transport.EncodeResponse,
-	)
-
-	getSignInHandler := httptransport.NewServer(
-		endpoint.MakeSignInEndpoint(svc),
-		transport.DecodeRequestWithBody(entity.UsernamePasswordRequest{}),
-		transport.EncodeResponse,
-	)
-
-	getLogOutHandler := httptransport.NewServer(
-		endpoint.MakeLogOutEndpoint(svc),
-		transport.DecodeRequestWithHeader(entity.Token{}),
-		transport.EncodeResponse,
-	)
-
-	getAllUsersHandler := httptransport.NewServer(
-		endpoint.MakeGetAllUsersEndpoint(svc),
-		transport.DecodeRequestWithoutBody(),
-		transport.EncodeResponse,
-	)
-
-	getProfileHandler := httptransport.NewServer(
-		endpoint.MakeProfileEndpoint(svc),
-		transport.DecodeRequestWithHeader(entity.Token{}),
-		transport.EncodeResponse,
-	)
-
-	getDeleteAccountHandler := httptransport.NewServer(
-		endpoint.MakeDeleteAccountEndpoint(svc),
-		transport.DecodeRequestWithHeader(entity.Token{}),
-		transport.EncodeResponse,
-	)
+func runServer(cfg *server.Config, infServ *service.InfoServices) {
+	svc, closeService, err := config.NewService(infServ)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	router := mux.NewRouter()
-	router.Methods(http.MethodPost).Path("/signup").Handler(getSignUpHandler)
-	router.Methods(http.MethodPost).Path("/signin").Handler(getSignInHandler)
-	router.Methods(http.MethodPost).Path("/logout").Handler(getLogOutHandler)
-	router.Methods(http.MethodGet).Path("/users").Handler(getAllUsersHandler)
-	router.Methods(http.MethodPost).Path("/profile").Handler(getProfileHandler)
-	router.Methods(http.MethodDelete).Path("/profile").Handler(getDeleteAccountHandler)
+	log.Println("ListenAndServe on localhost:" + cfg.Port)
+	log.Println(server.ListenAndServe(*cfg, server.NewHandler(svc, infServ.Validator, *cfg)))
 
-	log.Println("ListenAndServe on localhost:" + os.Getenv("PORT"))
-	log.Println(http.ListenAndServe(":"+port, router))
+	if err = closeService(); err != nil {
+		log.Println(err)
+	}
 }
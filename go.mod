@@ -6,9 +6,13 @@ require (
 	github.com/cfabrica46/gokit-crud/app v0.0.0-20220729041010-8de39b2eda0e
 	github.com/cfabrica46/gokit-crud/database-app v0.0.0-20220729041010-8de39b2eda0e
 	github.com/go-kit/kit v0.12.0
+	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/gorilla/mux v1.8.0
 	github.com/joho/godotenv v1.4.0
+	github.com/mattn/go-sqlite3 v1.14.22
 	github.com/stretchr/testify v1.8.0
+	golang.org/x/crypto v0.8.0
+	golang.org/x/text v0.13.0
 )
 
 require (
@@ -21,7 +25,7 @@ require (
 	github.com/golang-jwt/jwt v3.2.2+incompatible // indirect
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad // indirect
+	golang.org/x/sys v0.7.0 // indirect
 	gopkg.in/tomb.v1 v1.0.0-20141024135613-dd632973f1e7 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
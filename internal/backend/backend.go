@@ -0,0 +1,85 @@
+// Package backend declares the operations the gateway needs from the storage
+// and token services, whatever implements them.
+package backend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"app/internal/entity"
+)
+
+// ErrResponse is matched by the errors a backend reports in its answer, as
+// opposed to the ones of failing to reach it.
+var ErrResponse = errors.New("error in backend response")
+
+// ErrNotSupported is matched by the errors of operations the backend does not
+// serve.
+var ErrNotSupported = errors.New("operation not supported by the backend")
+
+// ResponseError is an error reported by a backend with Message.
+type ResponseError struct {
+	Message string
+}
+
+func (e *ResponseError) Error() string {
+	return e.Message
+}
+
+func (e *ResponseError) Is(target error) bool {
+	return target == ErrResponse //nolint:errorlint
+}
+
+// StorageClient keeps the users.
+type StorageClient interface {
+	CreateUser(ctx context.Context, user entity.UsernamePasswordEmailRequest) error
+	// GetIDByUsername returns 0 when no user has username.
+	GetIDByUsername(ctx context.Context, username string) (int, error)
+	// GetUserByCredentials checks a password the storage keeps as is.
+	GetUserByCredentials(ctx context.Context, username, password string) (entity.User, error)
+	GetUserByID(ctx context.Context, id int) (entity.User, error)
+	UpdatePassword(ctx context.Context, id int, password string) error
+	// SetDisabled marks the user as disabled, or not, for every gateway.
+	SetDisabled(ctx context.Context, id int, disabled bool) error
+	// SetRole sets the role of the user for every gateway. Both fail with
+	// ErrNotSupported when the storage does not keep them.
+	SetRole(ctx context.Context, id int, role string) error
+	DeleteUser(ctx context.Context, id int) error
+	// ListUsers returns every user with the zero options. paged is false
+	// when the storage ignored the options and sent every user.
+	ListUsers(ctx context.Context, options entity.ListUsersOptions) (page entity.UsersPage, paged bool, err error)
+}
+
+// TokenClient issues and keeps the tokens.
+type TokenClient interface {
+	Generate(ctx context.Context, claims entity.Claims, secret string) (string, error)
+	Store(ctx context.Context, token string) error
+	// Check reports whether token is stored and not revoked.
+	Check(ctx context.Context, token string) (bool, error)
+	Extract(ctx context.Context, token, secret string) (entity.Claims, error)
+	Revoke(ctx context.Context, token string) error
+	// RevokeUser revokes every token issued to the user id. It fails with
+	// ErrNotSupported when the backend cannot.
+	RevokeUser(ctx context.Context, id int) error
+}
+
+// SessionStore keeps the metadata of the sessions, one per token.
+type SessionStore interface {
+	CreateSession(ctx context.Context, session entity.Session) error
+	// TouchSession sets the last use of the session of token to at.
+	TouchSession(ctx context.Context, token string, at time.Time) error
+	ListSessions(ctx context.Context, userID int) ([]entity.Session, error)
+	GetSession(ctx context.Context, id string) (entity.Session, error)
+	DeleteSession(ctx context.Context, token string) error
+	DeleteUserSessions(ctx context.Context, userID int) error
+}
+
+// LoginHistory keeps the sign-in attempts of the users. Attempts are never
+// changed, but a history may drop the oldest ones of a user to bound its size,
+// so it is not an audit log.
+type LoginHistory interface {
+	AppendLogin(ctx context.Context, attempt entity.LoginAttempt) error
+	// ListLogins returns the attempts of the user, the last one first.
+	ListLogins(ctx context.Context, userID int) ([]entity.LoginAttempt, error)
+}
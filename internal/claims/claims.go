@@ -0,0 +1,114 @@
+// Package claims signs and verifies the HS256 JWTs of the token service, so
+// the gateway can read a token without asking the service.
+package claims
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"app/internal/entity"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrEmptySecret = errors.New("empty token secret")
+	ErrInvalid     = errors.New("invalid token")
+	ErrExpired     = errors.New("token expired")
+)
+
+// claims are the claims of the tokens the token service issues.
+type claims struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+	UserID int `json:"id"`
+}
+
+// Sign returns a token for user with the token ID id, issued at issuedAt.
+func Sign(secret []byte, user entity.Claims, id string, issuedAt time.Time) (token string, err error) {
+	if len(secret) == 0 {
+		return "", ErrEmptySecret
+	}
+
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       id,
+			IssuedAt: jwt.NewNumericDate(issuedAt),
+		},
+	}).SignedString(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return token, nil
+}
+
+// VerifierConfig configures a Verifier.
+type VerifierConfig struct {
+	// Leeway is the clock difference tolerated on exp, nbf and iat.
+	Leeway time.Duration
+	// RequireExpiry rejects tokens without exp, the token service does not
+	// set one.
+	RequireExpiry bool
+}
+
+// Verifier checks the signature and times of tokens signed with a secret.
+type Verifier struct {
+	secret  []byte
+	options []jwt.ParserOption
+}
+
+// NewVerifier returns a Verifier reading the time from now, nil for
+// time.Now.
+func NewVerifier(secret []byte, cfg VerifierConfig, now func() time.Time) (*Verifier, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithTimeFunc(now),
+		jwt.WithIssuedAt(),
+	}
+
+	if cfg.RequireExpiry {
+		options = append(options, jwt.WithExpirationRequired())
+	}
+
+	return &Verifier{secret: secret, options: options}, nil
+}
+
+// Verify returns the claims of token when it is signed with the secret and
+// valid at this time.
+func (v *Verifier) Verify(token string) (user entity.Claims, err error) {
+	var parsed claims
+
+	if _, err = jwt.ParseWithClaims(token, &parsed, func(*jwt.Token) (any, error) {
+		return v.secret, nil
+	}, v.options...); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return entity.Claims{}, ErrExpired
+		}
+
+		return entity.Claims{}, fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+	}
+
+	return entity.Claims{
+		ID:       parsed.UserID,
+		Username: parsed.Username,
+		Email:    parsed.Email,
+		Role:     parsed.Role,
+	}, nil
+}
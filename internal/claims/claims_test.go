@@ -0,0 +1,137 @@
+package claims_test
+
+import (
+	"testing"
+	"time"
+
+	"app/internal/claims"
+	"app/internal/entity"
+	"app/internal/entity/mock"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	user := entity.Claims{ID: mock.IDTest, Username: mock.UsernameTest, Email: mock.EmailTest, Role: entity.RoleUser}
+
+	signed, err := claims.Sign([]byte(mock.SecretTest), user, "id", now)
+	assert.Nil(t, err)
+
+	for _, tt := range []struct {
+		name    string
+		inToken string
+		inCfg   claims.VerifierConfig
+		outErr  error
+	}{
+		{
+			name:    mock.NameNoError,
+			inToken: signed,
+		},
+		{
+			name:    "NoErrorWithinLeeway",
+			inToken: newTokenTest(t, mock.SecretTest, jwt.SigningMethodHS256, now.Add(-time.Second)),
+			inCfg:   claims.VerifierConfig{Leeway: time.Minute, RequireExpiry: true},
+		},
+		{
+			name:    "ErrorExpired",
+			inToken: newTokenTest(t, mock.SecretTest, jwt.SigningMethodHS256, now.Add(-time.Second)),
+			outErr:  claims.ErrExpired,
+		},
+		{
+			name:    "ErrorRequireExpiry",
+			inToken: signed,
+			inCfg:   claims.VerifierConfig{RequireExpiry: true},
+			outErr:  claims.ErrInvalid,
+		},
+		{
+			name:    "ErrorSecret",
+			inToken: newTokenTest(t, "other", jwt.SigningMethodHS256, now.Add(time.Hour)),
+			outErr:  claims.ErrInvalid,
+		},
+		{
+			name:    "ErrorAlgorithm",
+			inToken: newTokenTest(t, mock.SecretTest, jwt.SigningMethodHS512, now.Add(time.Hour)),
+			outErr:  claims.ErrInvalid,
+		},
+		{
+			name:    "ErrorNone",
+			inToken: "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJpZCI6MX0.",
+			outErr:  claims.ErrInvalid,
+		},
+		{
+			name:    "ErrorMalformed",
+			inToken: mock.TokenTest,
+			outErr:  claims.ErrInvalid,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			verifier, err := claims.NewVerifier([]byte(mock.SecretTest), tt.inCfg, func() time.Time { return now })
+			assert.Nil(t, err)
+
+			got, err := verifier.Verify(tt.inToken)
+			if tt.outErr != nil {
+				assert.ErrorIs(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, user, got)
+		})
+	}
+}
+
+func TestVerifyIssuedAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	token, err := claims.Sign([]byte(mock.SecretTest), entity.Claims{ID: mock.IDTest}, "id", now.Add(time.Minute))
+	assert.Nil(t, err)
+
+	verifier, err := claims.NewVerifier([]byte(mock.SecretTest), claims.VerifierConfig{}, func() time.Time { return now })
+	assert.Nil(t, err)
+
+	_, err = verifier.Verify(token)
+	assert.ErrorIs(t, err, claims.ErrInvalid)
+
+	verifier, err = claims.NewVerifier(
+		[]byte(mock.SecretTest),
+		claims.VerifierConfig{Leeway: time.Minute},
+		func() time.Time { return now },
+	)
+	assert.Nil(t, err)
+
+	_, err = verifier.Verify(token)
+	assert.Nil(t, err)
+
+	_, err = claims.NewVerifier(nil, claims.VerifierConfig{}, nil)
+	assert.ErrorIs(t, err, claims.ErrEmptySecret)
+
+	_, err = claims.Sign(nil, entity.Claims{}, "id", now)
+	assert.ErrorIs(t, err, claims.ErrEmptySecret)
+}
+
+// newTokenTest signs, with method, the claims of the user of the tests
+// expiring at expiresAt.
+func newTokenTest(t *testing.T, secret string, method jwt.SigningMethod, expiresAt time.Time) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, jwt.MapClaims{
+		"id":       mock.IDTest,
+		"username": mock.UsernameTest,
+		"email":    mock.EmailTest,
+		"role":     entity.RoleUser,
+		"exp":      expiresAt.Unix(),
+	}).SignedString([]byte(secret))
+	assert.Nil(t, err)
+
+	return token
+}
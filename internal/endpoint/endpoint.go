@@ -4,18 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 
 	"app/internal/entity"
 	"app/internal/service"
 
 	"github.com/go-kit/kit/endpoint"
+	httptransport "github.com/go-kit/kit/transport/http"
 )
 
 var ErrRequest = errors.New("error to request")
 
-// MakeSignUpEndpoint ...
+// MakeSignUpEndpoint signs up the client of the request, see clientOf.
 func MakeSignUpEndpoint(svc service.Service) endpoint.Endpoint {
-	return func(_ context.Context, request any) (any, error) {
+	return func(ctx context.Context, request any) (any, error) {
 		var errMessage string
 
 		req, ok := request.(entity.UsernamePasswordEmailRequest)
@@ -23,18 +25,18 @@ func MakeSignUpEndpoint(svc service.Service) endpoint.Endpoint {
 			return nil, fmt.Errorf("%w: isn't of type GenerateTokenRequest", ErrRequest)
 		}
 
-		token, err := svc.SignUp(req.Username, req.Password, req.Email)
+		token, err := svc.SignUp(req.Username, req.Password, req.Email, clientOf(ctx))
 		if err != nil {
 			errMessage = err.Error()
 		}
 
-		return entity.TokenErrorResponse{Token: token, Err: errMessage}, nil
+		return entity.TokenErrorResponse{Token: token, Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
 	}
 }
 
-// MakeSignInEndpoint ...
+// MakeSignInEndpoint signs in the client of the request, see clientOf.
 func MakeSignInEndpoint(svc service.Service) endpoint.Endpoint {
-	return func(_ context.Context, request any) (any, error) {
+	return func(ctx context.Context, request any) (any, error) {
 		var errMessage string
 
 		req, ok := request.(entity.UsernamePasswordRequest)
@@ -42,12 +44,12 @@ func MakeSignInEndpoint(svc service.Service) endpoint.Endpoint {
 			return nil, fmt.Errorf("%w: isn't of type GenerateTokenRequest", ErrRequest)
 		}
 
-		token, err := svc.SignIn(req.Username, req.Password)
+		token, err := svc.SignIn(req.Username, req.Password, clientOf(ctx))
 		if err != nil {
 			errMessage = err.Error()
 		}
 
-		return entity.TokenErrorResponse{Token: token, Err: errMessage}, nil
+		return entity.TokenErrorResponse{Token: token, Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
 	}
 }
 
@@ -66,10 +68,103 @@ func MakeLogOutEndpoint(svc service.Service) endpoint.Endpoint {
 			errMessage = err.Error()
 		}
 
-		return entity.ErrorResponse{Err: errMessage}, nil
+		return entity.ErrorResponse{Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
 	}
 }
 
+// MakeLogOutAllEndpoint ends every session of the owner of the token.
+func MakeLogOutAllEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(_ context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.Token)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type Token", ErrRequest)
+		}
+
+		err := svc.LogOutAll(req.Token)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.ErrorResponse{Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
+	}
+}
+
+// MakeListSessionsEndpoint ...
+func MakeListSessionsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(_ context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.Token)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type Token", ErrRequest)
+		}
+
+		sessions, err := svc.ListSessions(req.Token)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.SessionsErrorResponse{
+			Sessions: sessions,
+			Err:      errMessage,
+			Failure:  entity.Failure{Cause: err},
+		}, nil
+	}
+}
+
+// MakeRevokeSessionEndpoint ...
+func MakeRevokeSessionEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(_ context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.TokenSessionRequest)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type TokenSessionRequest", ErrRequest)
+		}
+
+		err := svc.RevokeSession(req.Token, req.ID)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.ErrorResponse{Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
+	}
+}
+
+// MakeLoginHistoryEndpoint ...
+func MakeLoginHistoryEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(_ context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.Token)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type Token", ErrRequest)
+		}
+
+		logins, err := svc.LoginHistory(req.Token)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.LoginsErrorResponse{Logins: logins, Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
+	}
+}
+
+// clientOf reads the client from the request context that the go-kit
+// PopulateRequestContext filled, the IP is the peer of the connection.
+func clientOf(ctx context.Context) (client entity.Client) {
+	client.UserAgent, _ = ctx.Value(httptransport.ContextKeyRequestUserAgent).(string)
+	client.IP, _ = ctx.Value(httptransport.ContextKeyRequestRemoteAddr).(string)
+
+	if host, _, err := net.SplitHostPort(client.IP); err == nil {
+		client.IP = host
+	}
+
+	return client
+}
+
 // MakeGetAllUsersEndpoint ...
 func MakeGetAllUsersEndpoint(svc service.Service) endpoint.Endpoint {
 	return func(_ context.Context, _ any) (any, error) {
@@ -80,7 +175,76 @@ func MakeGetAllUsersEndpoint(svc service.Service) endpoint.Endpoint {
 			errMessage = err.Error()
 		}
 
-		return entity.UsersErrorResponse{Users: users, Err: errMessage}, nil
+		return entity.UsersErrorResponse{Users: users, Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
+	}
+}
+
+// MakeListUsersEndpoint ...
+func MakeListUsersEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(_ context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.ListUsersRequest)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type ListUsersRequest", ErrRequest)
+		}
+
+		page, err := svc.ListUsers(req.ListUsersOptions)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.UsersErrorResponse{
+			Users:      page.Users,
+			NextCursor: page.NextCursor,
+			Total:      page.Total,
+			Err:        errMessage,
+			Failure:    entity.Failure{Cause: err},
+		}, nil
+	}
+}
+
+// MakeExportUsersEndpoint returns a lazy export, the users are fetched while
+// the response is written.
+func MakeExportUsersEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, _ any) (any, error) {
+		return entity.UsersExport{
+			Each: func(yield func(entity.User) error) error {
+				return svc.ExportUsers(ctx, yield)
+			},
+		}, nil
+	}
+}
+
+// MakeImportUsersEndpoint ...
+func MakeImportUsersEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.ImportUsersRequest)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type ImportUsersRequest", ErrRequest)
+		}
+
+		var (
+			rows []entity.ImportRow
+			err  error
+		)
+
+		// The body is only read here, after the middlewares authorized the
+		// request.
+		if req.Parse != nil {
+			if rows, err = req.Parse(); err != nil {
+				return nil, err
+			}
+		}
+
+		report, err := svc.ImportUsers(ctx, rows, req.ImportOptions)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.ImportReportErrorResponse{Report: report, Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
 	}
 }
 
@@ -99,7 +263,7 @@ func MakeProfileEndpoint(svc service.Service) endpoint.Endpoint {
 			errMessage = err.Error()
 		}
 
-		return entity.UserErrorResponse{User: user, Err: errMessage}, nil
+		return entity.UserErrorResponse{User: user, Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
 	}
 }
 
@@ -118,6 +282,82 @@ func MakeDeleteAccountEndpoint(svc service.Service) endpoint.Endpoint {
 			errMessage = err.Error()
 		}
 
-		return entity.ErrorResponse{Err: errMessage}, nil
+		return entity.ErrorResponse{Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
+	}
+}
+
+// MakeChangePasswordEndpoint ...
+func MakeChangePasswordEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(_ context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.ChangePasswordRequest)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type ChangePasswordRequest", ErrRequest)
+		}
+
+		err := svc.ChangePassword(req.Token, req.OldPassword, req.NewPassword)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.ErrorResponse{Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
+	}
+}
+
+// MakeGetUserEndpoint ...
+func MakeGetUserEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(_ context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.TokenIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type TokenIDRequest", ErrRequest)
+		}
+
+		user, err := svc.GetUser(req.ID)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.UserErrorResponse{User: user, Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
+	}
+}
+
+// MakeDisableUserEndpoint ...
+func MakeDisableUserEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(_ context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.TokenIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type TokenIDRequest", ErrRequest)
+		}
+
+		err := svc.DisableUser(req.ID)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.ErrorResponse{Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
+	}
+}
+
+// MakeDeleteUserEndpoint ...
+func MakeDeleteUserEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(_ context.Context, request any) (any, error) {
+		var errMessage string
+
+		req, ok := request.(entity.TokenIDRequest)
+		if !ok {
+			return nil, fmt.Errorf("%w: isn't of type TokenIDRequest", ErrRequest)
+		}
+
+		err := svc.DeleteUser(req.ID)
+		if err != nil {
+			errMessage = err.Error()
+		}
+
+		return entity.ErrorResponse{Err: errMessage, Failure: entity.Failure{Cause: err}}, nil
 	}
 }
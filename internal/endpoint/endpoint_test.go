@@ -15,6 +15,8 @@ import (
 
 	httpMock "app/internal/service/mock"
 
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	httptransport "github.com/go-kit/kit/transport/http"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -177,7 +179,9 @@ func TestSignInEndpoint(t *testing.T) {
 				Token string `json:"token"`
 				Err   string `json:"err"`
 				User  entity.User
+				ID    int `json:"id"`
 			}{
+				ID: mock.IDTest,
 				User: entity.User{
 					ID:       mock.IDTest,
 					Username: mock.UsernameTest,
@@ -232,6 +236,51 @@ func TestSignInEndpoint(t *testing.T) {
 	}
 }
 
+// clientServiceTest keeps the client it is signed up or in from.
+type clientServiceTest struct {
+	service.Service
+	client *entity.Client
+}
+
+func (s clientServiceTest) SignUp(_, _, _ string, client entity.Client) (string, error) {
+	*s.client = client
+
+	return mock.TokenTest, nil
+}
+
+func (s clientServiceTest) SignIn(_, _ string, client entity.Client) (string, error) {
+	*s.client = client
+
+	return mock.TokenTest, nil
+}
+
+func TestSignEndpointsClient(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), httptransport.ContextKeyRequestRemoteAddr, "192.0.2.1:54321")
+	ctx = context.WithValue(ctx, httptransport.ContextKeyRequestUserAgent, "curl/8.0")
+
+	for _, tt := range []struct {
+		name     string
+		endpoint func(service.Service) kitendpoint.Endpoint
+		in       any
+	}{
+		{name: "SignUp", endpoint: endpoint.MakeSignUpEndpoint, in: entity.UsernamePasswordEmailRequest{}},
+		{name: "SignIn", endpoint: endpoint.MakeSignInEndpoint, in: entity.UsernamePasswordRequest{}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var client entity.Client
+
+			_, err := tt.endpoint(clientServiceTest{client: &client})(ctx, tt.in)
+			assert.Nil(t, err)
+			assert.Equal(t, entity.Client{IP: "192.0.2.1", UserAgent: "curl/8.0"}, client)
+		})
+	}
+}
+
 func TestLogOutEndpoint(t *testing.T) {
 	t.Parallel()
 
@@ -324,6 +373,79 @@ func TestLogOutEndpoint(t *testing.T) {
 	}
 }
 
+func TestLogOutAllEndpoint(t *testing.T) {
+	t.Parallel()
+
+	infoServiceTest := service.InfoServices{
+		DBHost:    mock.DBHostTest,
+		DBPort:    mock.PortTest,
+		TokenHost: mock.TokenHostTest,
+		TokenPort: mock.PortTest,
+		Secret:    mock.SecretTest,
+	}
+
+	for _, tt := range []struct {
+		name    string
+		in      any
+		inRoute string
+		outErr  string
+	}{
+		{
+			name: mock.NameNoError,
+			in:   entity.Token{Token: mock.TokenTest},
+		},
+		{
+			name:   nameErrorRequest,
+			in:     incorrectRequest{incorrect: true},
+			outErr: "isn't of type",
+		},
+		{
+			name:    "ErrorRevokeUser",
+			in:      entity.Token{Token: mock.TokenTest},
+			inRoute: "/tokens",
+			outErr:  service.ErrWebServer.Error(),
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockClient := httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				body := `{"check":true,"id":1}`
+				if req.URL.Path == tt.inRoute {
+					body = `{"err":"error"}`
+				}
+
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+				}, nil
+			})
+
+			r, err := endpoint.MakeLogOutAllEndpoint(service.NewService(mockClient, &infoServiceTest))(
+				context.TODO(),
+				tt.in,
+			)
+			if tt.name == nameErrorRequest {
+				assert.ErrorContains(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			result, ok := r.(entity.ErrorResponse)
+			assert.True(t, ok)
+
+			if tt.outErr == "" {
+				assert.Empty(t, result.Err)
+			} else {
+				assert.Contains(t, result.Err, tt.outErr)
+			}
+		})
+	}
+}
+
 func TestGetAllUsersEndpoint(t *testing.T) {
 	t.Parallel()
 
@@ -346,7 +468,6 @@ func TestGetAllUsersEndpoint(t *testing.T) {
 				{
 					ID:       mock.IDTest,
 					Username: mock.UsernameTest,
-					Password: mock.PasswordTest,
 					Email:    mock.EmailTest,
 				},
 			},
@@ -433,8 +554,8 @@ func TestProfileEndpoint(t *testing.T) {
 			outUser: entity.User{
 				ID:       mock.IDTest,
 				Username: mock.UsernameTest,
-				Password: mock.PasswordTest,
 				Email:    mock.EmailTest,
+				Role:     entity.RoleUser,
 			},
 			outErr: "",
 		},
@@ -615,3 +736,168 @@ func TestDeleteAccountEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestAdminEndpoints(t *testing.T) {
+	t.Parallel()
+
+	infoServiceTest := service.InfoServices{
+		DBHost:    mock.DBHostTest,
+		DBPort:    mock.PortTest,
+		TokenHost: mock.TokenHostTest,
+		TokenPort: mock.PortTest,
+		Secret:    mock.SecretTest,
+	}
+
+	for _, tt := range []struct {
+		makeEndpoint func(service.Service) kitendpoint.Endpoint
+		in           any
+		name         string
+		outErr       string
+	}{
+		{
+			name:         mock.NameNoError + "GetUser",
+			makeEndpoint: endpoint.MakeGetUserEndpoint,
+			in:           entity.TokenIDRequest{Token: mock.TokenTest, ID: mock.IDTest},
+		},
+		{
+			name:         mock.NameNoError + "DisableUser",
+			makeEndpoint: endpoint.MakeDisableUserEndpoint,
+			in:           entity.TokenIDRequest{Token: mock.TokenTest, ID: mock.IDTest},
+		},
+		{
+			name:         mock.NameNoError + "DeleteUser",
+			makeEndpoint: endpoint.MakeDeleteUserEndpoint,
+			in:           entity.TokenIDRequest{Token: mock.TokenTest, ID: mock.IDTest},
+		},
+		{
+			name:         nameErrorRequest + "GetUser",
+			makeEndpoint: endpoint.MakeGetUserEndpoint,
+			in:           entity.Token{Token: mock.TokenTest},
+			outErr:       "isn't of type",
+		},
+		{
+			name:         nameErrorRequest + "DisableUser",
+			makeEndpoint: endpoint.MakeDisableUserEndpoint,
+			in:           entity.Token{Token: mock.TokenTest},
+			outErr:       "isn't of type",
+		},
+		{
+			name:         nameErrorRequest + "DeleteUser",
+			makeEndpoint: endpoint.MakeDeleteUserEndpoint,
+			in:           entity.Token{Token: mock.TokenTest},
+			outErr:       "isn't of type",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			jsonData := []byte(`{"user":{"id":1,"username":"username"}}`)
+
+			mockClient := httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader(jsonData)),
+				}, nil
+			})
+
+			svc := service.NewService(
+				mockClient,
+				&infoServiceTest,
+			)
+
+			r, err := tt.makeEndpoint(svc)(context.TODO(), tt.in)
+			if tt.outErr != "" {
+				assert.ErrorContains(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			switch result := r.(type) {
+			case entity.UserErrorResponse:
+				assert.Empty(t, result.Err)
+				assert.Equal(t, mock.IDTest, result.User.ID)
+			case entity.ErrorResponse:
+				assert.Empty(t, result.Err)
+			default:
+				assert.Fail(t, mock.ErrNotTypeIndicated.Error())
+			}
+		})
+	}
+}
+
+func TestListUsersEndpoint(t *testing.T) {
+	t.Parallel()
+
+	infoServiceTest := service.InfoServices{
+		DBHost:    mock.DBHostTest,
+		DBPort:    mock.PortTest,
+		TokenHost: mock.TokenHostTest,
+		TokenPort: mock.PortTest,
+		Secret:    mock.SecretTest,
+	}
+
+	for _, tt := range []struct {
+		in       any
+		name     string
+		outErr   string
+		outUsers int
+	}{
+		{
+			name:     mock.NameNoError,
+			in:       entity.ListUsersRequest{Token: mock.TokenTest},
+			outUsers: 1,
+		},
+		{
+			name:   "ErrorOptions",
+			in:     entity.ListUsersRequest{ListUsersOptions: entity.ListUsersOptions{Sort: "email"}},
+			outErr: service.ErrInvalidListOptions.Error(),
+		},
+		{
+			name:   nameErrorRequest,
+			in:     incorrectRequest{incorrect: true},
+			outErr: "isn't of type",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			jsonData := []byte(`{"users":[{"id":1,"username":"username"}]}`)
+
+			mockClient := httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader(jsonData)),
+				}, nil
+			})
+
+			svc := service.NewService(
+				mockClient,
+				&infoServiceTest,
+			)
+
+			r, err := endpoint.MakeListUsersEndpoint(svc)(context.TODO(), tt.in)
+			if tt.name == nameErrorRequest {
+				assert.ErrorContains(t, err, tt.outErr)
+
+				return
+			}
+
+			result, ok := r.(entity.UsersErrorResponse)
+			assert.True(t, ok)
+
+			if tt.outErr != "" {
+				assert.Contains(t, result.Err, tt.outErr)
+
+				return
+			}
+
+			assert.Empty(t, result.Err)
+			assert.Len(t, result.Users, tt.outUsers)
+			assert.Equal(t, tt.outUsers, *result.Total)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package endpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"app/internal/entity"
+	"app/internal/service"
+	"app/internal/validation"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+type claimsContextKey struct{}
+
+type tokenRequest interface {
+	GetToken() string
+}
+
+// MakeAuthorizationMiddleware rejects requests whose token does not belong to
+// a user with one of the given roles. The claims of authorized requests are
+// stored in the context, see ClaimsFromContext.
+func MakeAuthorizationMiddleware(svc service.Service, roles ...string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request any) (any, error) {
+			req, ok := request.(tokenRequest)
+			if !ok {
+				return nil, fmt.Errorf("%w: doesn't carry a token", ErrRequest)
+			}
+
+			claims, err := svc.Authorize(req.GetToken(), roles...)
+			if err != nil {
+				return entity.ErrorResponse{Err: err.Error(), Failure: entity.Failure{Cause: err}}, nil
+			}
+
+			return next(context.WithValue(ctx, claimsContextKey{}, claims), request)
+		}
+	}
+}
+
+// MakeValidationMiddleware normalizes requests with v before they reach the
+// endpoint, invalid ones are answered with an entity.ValidationErrorResponse.
+// A nil v only checks the format of the fields.
+func MakeValidationMiddleware(v *validation.Validator) endpoint.Middleware {
+	if v == nil {
+		v = validation.NewValidator(nil, nil)
+	}
+
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request any) (any, error) {
+			var errs validation.Errors
+
+			normalized, err := v.Validate(request)
+			if errors.As(err, &errs) {
+				return entity.ValidationErrorResponse{
+					Err:    validation.ErrInvalidRequest.Error(),
+					Fields: errs,
+				}, nil
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			return next(ctx, normalized)
+		}
+	}
+}
+
+// ClaimsFromContext ...
+func ClaimsFromContext(ctx context.Context) (claims entity.Claims, ok bool) {
+	claims, ok = ctx.Value(claimsContextKey{}).(entity.Claims)
+
+	return claims, ok
+}
@@ -0,0 +1,206 @@
+package endpoint_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"app/internal/endpoint"
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/service"
+	"app/internal/validation"
+
+	httpMock "app/internal/service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizationMiddleware(t *testing.T) {
+	t.Parallel()
+
+	infoServiceTest := service.InfoServices{
+		DBHost:    mock.DBHostTest,
+		DBPort:    mock.PortTest,
+		TokenHost: mock.TokenHostTest,
+		TokenPort: mock.PortTest,
+		Secret:    mock.SecretTest,
+	}
+
+	for _, tt := range []struct {
+		in      any
+		name    string
+		inRole  string
+		outErr  string
+		outNext bool
+	}{
+		{
+			name:    mock.NameNoError,
+			in:      entity.TokenIDRequest{Token: mock.TokenTest, ID: mock.IDTest},
+			inRole:  entity.RoleAdmin,
+			outNext: true,
+		},
+		{
+			name:   "ErrorForbidden",
+			in:     entity.Token{Token: mock.TokenTest},
+			inRole: entity.RoleUser,
+			outErr: service.ErrForbidden.Error(),
+		},
+		{
+			name:   nameErrorRequest,
+			in:     incorrectRequest{incorrect: true},
+			outErr: "doesn't carry a token",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			jsonData := []byte(`{"id":1,"username":"username","user":{"id":1,"role":"` + tt.inRole + `"},"check":true}`)
+
+			mockClient := httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader(jsonData)),
+				}, nil
+			})
+
+			svc := service.NewService(
+				mockClient,
+				&infoServiceTest,
+			)
+
+			var calledNext bool
+
+			next := func(ctx context.Context, _ any) (any, error) {
+				claims, ok := endpoint.ClaimsFromContext(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, entity.RoleAdmin, claims.Role)
+
+				calledNext = true
+
+				return entity.ErrorResponse{}, nil
+			}
+
+			r, err := endpoint.MakeAuthorizationMiddleware(svc, entity.RoleAdmin)(next)(context.TODO(), tt.in)
+
+			assert.Equal(t, tt.outNext, calledNext)
+
+			if tt.name == nameErrorRequest {
+				assert.ErrorContains(t, err, tt.outErr)
+
+				return
+			}
+
+			result, ok := r.(entity.ErrorResponse)
+			assert.True(t, ok)
+			assert.Contains(t, result.Err, tt.outErr)
+		})
+	}
+}
+
+func TestAuthorizationMiddlewareImport(t *testing.T) {
+	t.Parallel()
+
+	jsonData := []byte(`{"id":1,"username":"username","user":{"id":1,"role":"user"},"check":true}`)
+
+	mockClient := httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewReader(jsonData)),
+		}, nil
+	})
+
+	svc := service.NewService(mockClient, &service.InfoServices{
+		DBHost:    mock.DBHostTest,
+		DBPort:    mock.PortTest,
+		TokenHost: mock.TokenHostTest,
+		TokenPort: mock.PortTest,
+		Secret:    mock.SecretTest,
+	})
+
+	var parsed bool
+
+	request := entity.ImportUsersRequest{
+		Token: mock.TokenTest,
+		Parse: func() ([]entity.ImportRow, error) {
+			parsed = true
+
+			return nil, nil
+		},
+	}
+
+	importEndpoint := endpoint.MakeAuthorizationMiddleware(svc, entity.RoleAdmin)(endpoint.MakeImportUsersEndpoint(svc))
+
+	r, err := importEndpoint(context.TODO(), request)
+	assert.Nil(t, err)
+
+	result, ok := r.(entity.ErrorResponse)
+	assert.True(t, ok)
+	assert.Contains(t, result.Err, service.ErrForbidden.Error())
+
+	// The body of a forbidden import is never read.
+	assert.False(t, parsed)
+}
+
+func TestValidationMiddleware(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		in      any
+		out     any
+		name    string
+		outNext bool
+	}{
+		{
+			name: mock.NameNoError,
+			in: entity.UsernamePasswordEmailRequest{
+				Username: "ｕｓｅｒｎａｍｅ",
+				Password: mock.PasswordTest,
+				Email:    mock.EmailTest,
+			},
+			out: entity.UsernamePasswordEmailRequest{
+				Username: mock.UsernameTest,
+				Password: mock.PasswordTest,
+				Email:    mock.EmailTest,
+			},
+			outNext: true,
+		},
+		{
+			name: "ErrorInvalid",
+			in: entity.UsernamePasswordEmailRequest{
+				Username: mock.UsernameTest,
+				Password: mock.PasswordTest,
+				Email:    "email",
+			},
+			out: entity.ValidationErrorResponse{
+				Err:    validation.ErrInvalidRequest.Error(),
+				Fields: []entity.FieldError{{Field: "email", Message: "must be an email address"}},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var next any
+
+			r, err := endpoint.MakeValidationMiddleware(nil)(func(_ context.Context, request any) (any, error) {
+				next = request
+
+				return request, nil
+			})(context.TODO(), tt.in)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.out, r)
+
+			if tt.outNext {
+				assert.Equal(t, tt.out, next)
+			} else {
+				assert.Nil(t, next)
+			}
+		})
+	}
+}
@@ -1,10 +1,51 @@
 package entity
 
+import "net/http"
+
 // ErrorResponse ...
 type ErrorResponse struct {
+	Failure
 	Err string `json:"err,omitempty"`
 }
 
+// Failure keeps the error behind the Err field of a response, so encoders
+// can tell its type. It is never encoded.
+type Failure struct {
+	Cause error `json:"-"`
+}
+
+// Failed implements the go-kit endpoint.Failer.
+func (f Failure) Failed() error {
+	return f.Cause
+}
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	Status   int          `json:"status"`
+}
+
+// FieldError names an invalid request field and why.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is sent with status 400 for invalid requests.
+type ValidationErrorResponse struct {
+	Err    string       `json:"err"`
+	Fields []FieldError `json:"fields"`
+}
+
+// StatusCode implements the go-kit StatusCoder.
+func (ValidationErrorResponse) StatusCode() int {
+	return http.StatusBadRequest
+}
+
 /*
 // UsernamePasswordEmailRequest (string, string, string) (string, error).
 type UsernamePasswordEmailRequest struct {
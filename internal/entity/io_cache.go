@@ -5,6 +5,7 @@ type IDUsernameEmailSecretRequest struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Secret   string `json:"secret"`
+	Role     string `json:"role,omitempty"`
 	ID       int    `json:"id"`
 }
 
@@ -19,8 +20,33 @@ type Token struct {
 	Token string `json:"token"`
 }
 
+// GetToken ...
+func (t Token) GetToken() string {
+	return t.Token
+}
+
+// TokenIDRequest ...
+type TokenIDRequest struct {
+	Token string `json:"token"`
+	ID    int    `json:"id"`
+}
+
+// GetToken ...
+func (t TokenIDRequest) GetToken() string {
+	return t.Token
+}
+
+// Claims ...
+type Claims struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	ID       int    `json:"id"`
+}
+
 // Token ...
 type TokenErrorResponse struct {
+	Failure
 	Token string `json:"token"`
 	Err   string `json:"err,omitempty"`
 }
@@ -29,6 +55,7 @@ type TokenErrorResponse struct {
 type IDUsernameEmailErrResponse struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Role     string `json:"role,omitempty"`
 	Err      string `json:"err,omitempty"`
 	ID       int    `json:"id"`
 }
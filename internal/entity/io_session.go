@@ -0,0 +1,70 @@
+package entity
+
+import "time"
+
+// Client is who sent a request, as far as the gateway can tell.
+type Client struct {
+	IP        string `json:"ip"`
+	UserAgent string `json:"userAgent"`
+}
+
+// Session is the metadata of one token, recorded when it is issued.
+type Session struct {
+	CreatedAt time.Time `json:"createdAt"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Token     string    `json:"-"`
+	ID        string    `json:"id"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Device    string    `json:"device"`
+	UserID    int       `json:"-"`
+	Current   bool      `json:"current"`
+}
+
+// TokenSessionRequest ...
+type TokenSessionRequest struct {
+	Token string `json:"token"`
+	ID    string `json:"id"`
+}
+
+// GetToken ...
+func (t TokenSessionRequest) GetToken() string {
+	return t.Token
+}
+
+// SessionsErrorResponse ...
+type SessionsErrorResponse struct {
+	Failure
+	Err      string    `json:"err,omitempty"`
+	Sessions []Session `json:"sessions"`
+}
+
+// LoginMethodPassword is the Method of the sign-ins with a password.
+const LoginMethodPassword string = "password"
+
+// The Reason of the failed sign-ins.
+const (
+	LoginReasonInvalidCredentials string = "invalid_credentials"
+	LoginReasonUserDisabled       string = "user_disabled"
+	LoginReasonError              string = "error"
+)
+
+// LoginAttempt is one sign-in attempt of a user, successful or not.
+type LoginAttempt struct {
+	Time        time.Time `json:"time"`
+	Method      string    `json:"method"`
+	IP          string    `json:"ip"`
+	UserAgent   string    `json:"userAgent"`
+	Device      string    `json:"device"`
+	Fingerprint string    `json:"-"`
+	Reason      string    `json:"reason,omitempty"`
+	UserID      int       `json:"-"`
+	Success     bool      `json:"success"`
+}
+
+// LoginsErrorResponse ...
+type LoginsErrorResponse struct {
+	Failure
+	Err    string         `json:"err,omitempty"`
+	Logins []LoginAttempt `json:"logins"`
+}
@@ -1,11 +1,19 @@
 package entity
 
+// Roles known by the gateway.
+const (
+	RoleUser  string = "user"
+	RoleAdmin string = "admin"
+)
+
 // User ...
 type User struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Email    string `json:"email"`
+	Role     string `json:"role,omitempty"`
 	ID       int    `json:"id"`
+	Disabled bool   `json:"disabled,omitempty"`
 }
 
 // ---
@@ -24,6 +32,37 @@ type UsernamePasswordRequest struct {
 	Password string `json:"password"`
 }
 
+// IDDisabledRequest ...
+type IDDisabledRequest struct {
+	ID       int  `json:"id"`
+	Disabled bool `json:"disabled"`
+}
+
+// IDRoleRequest ...
+type IDRoleRequest struct {
+	Role string `json:"role"`
+	ID   int    `json:"id"`
+}
+
+// IDPasswordRequest ...
+type IDPasswordRequest struct {
+	Password string `json:"password"`
+	ID       int    `json:"id"`
+}
+
+// ChangePasswordRequest carries the token from the header and the passwords
+// from the body.
+type ChangePasswordRequest struct {
+	Token       string `json:"-"`
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword"`
+}
+
+// GetToken ...
+func (r ChangePasswordRequest) GetToken() string {
+	return r.Token
+}
+
 // UsernameRequest ...
 type UsernameRequest struct {
 	Username string `json:"username"`
@@ -36,16 +75,115 @@ type UsernamePasswordEmailRequest struct {
 	Email    string `json:"email"`
 }
 
+// ListUsersOptions ...
+type ListUsersOptions struct {
+	Cursor         string `json:"cursor,omitempty"`
+	UsernamePrefix string `json:"usernamePrefix,omitempty"`
+	EmailDomain    string `json:"emailDomain,omitempty"`
+	Sort           string `json:"sort,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+	Offset         int    `json:"offset,omitempty"`
+}
+
+// ListUsersRequest ...
+type ListUsersRequest struct {
+	Token string `json:"token"`
+	ListUsersOptions
+}
+
+// GetToken ...
+func (l ListUsersRequest) GetToken() string {
+	return l.Token
+}
+
+// ImportRow is one parsed line of an import file, Err is set when the line
+// could not be read.
+type ImportRow struct {
+	Err  string                       `json:"err,omitempty"`
+	User UsernamePasswordEmailRequest `json:"user"`
+	Line int                          `json:"line"`
+}
+
+// ImportOptions ...
+type ImportOptions struct {
+	Concurrency int  `json:"concurrency"`
+	DryRun      bool `json:"dryRun"`
+}
+
+// ImportUsersRequest ... Parse reads the rows from the body, it is called
+// once the request is authorized.
+type ImportUsersRequest struct {
+	Parse func() ([]ImportRow, error) `json:"-"`
+	Token string                      `json:"token"`
+	ImportOptions
+}
+
+// GetToken ...
+func (i ImportUsersRequest) GetToken() string {
+	return i.Token
+}
+
 // ---
 
-// UsersErrorResponse ...
+// UsersPage ...
+type UsersPage struct {
+	Total      *int   `json:"total,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	Users      []User `json:"users"`
+}
+
+// UsersExport is streamed by the transport layer, Each calls yield once per
+// user.
+type UsersExport struct {
+	Each func(yield func(User) error) error
+}
+
+// UsersErrorResponse ... Paged is set by storage backends that applied the
+// ListUsersOptions themselves.
 type UsersErrorResponse struct {
-	Err   string `json:"err,omitempty"`
-	Users []User `json:"users"`
+	Failure
+	Total      *int   `json:"total,omitempty"`
+	Err        string `json:"err,omitempty"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	Users      []User `json:"users"`
+	Paged      bool   `json:"paged,omitempty"`
+}
+
+// Statuses of an ImportResult.
+const (
+	ImportCreated string = "created"
+	ImportSkipped string = "skipped"
+	ImportFailed  string = "failed"
+)
+
+// ImportResult ...
+type ImportResult struct {
+	Username string `json:"username"`
+	Status   string `json:"status"`
+	Err      string `json:"err,omitempty"`
+	Line     int    `json:"line"`
+}
+
+// ImportReport ... With DryRun nothing is written and Created counts the rows
+// that would have been created.
+type ImportReport struct {
+	Results []ImportResult `json:"results"`
+	Created int            `json:"created"`
+	Skipped int            `json:"skipped"`
+	Failed  int            `json:"failed"`
+	DryRun  bool           `json:"dryRun"`
+}
+
+// ImportReportErrorResponse ...
+type ImportReportErrorResponse struct {
+	Failure
+	Err    string       `json:"err,omitempty"`
+	Report ImportReport `json:"report"`
 }
 
 // UserErrorResponse ...
 type UserErrorResponse struct {
+	Failure
 	Err  string `json:"err,omitempty"`
 	User User   `json:"user"`
 }
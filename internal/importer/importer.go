@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"app/internal/entity"
+)
+
+// Formats understood by Parse.
+const (
+	FormatCSV    string = "csv"
+	FormatNDJSON string = "ndjson"
+
+	// MaxRows bounds the number of rows read from a single import.
+	MaxRows int = 10000
+
+	maxLineSize int = 64 * 1024
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown import format")
+	ErrTooManyRows   = errors.New("too many rows")
+	ErrMissingColumn = errors.New("missing column")
+	ErrLineTooLong   = errors.New("line too long")
+)
+
+// FormatFromContentType maps a Content-Type header to one of the formats.
+func FormatFromContentType(contentType string) (format string, err error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, contentType)
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, nil
+	case "application/x-ndjson", "application/ndjson", "application/jsonlines":
+		return FormatNDJSON, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, mediaType)
+	}
+}
+
+// Parse reads the rows of an import in the given format. Malformed lines do
+// not stop the parse, they are returned with Err set.
+func Parse(format string, r io.Reader) (rows []entity.ImportRow, err error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatNDJSON:
+		return ParseNDJSON(r)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// ParseCSV reads a CSV file whose header names the username, password and
+// email columns, in any order.
+func ParseCSV(r io.Reader) (rows []entity.ImportRow, err error) {
+	reader := csv.NewReader(&lineLimitReader{reader: r, limit: maxLineSize})
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"username", "password", "email"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingColumn, name)
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		if len(rows) == MaxRows {
+			return nil, fmt.Errorf("%w: limit is %d", ErrTooManyRows, MaxRows)
+		}
+
+		var row entity.ImportRow
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("failed to read csv: %w", err)
+			}
+
+			row.Line = parseErr.Line
+			row.Err = parseErr.Err.Error()
+			rows = append(rows, row)
+
+			continue
+		}
+
+		row.Line, _ = reader.FieldPos(0)
+		row.User = entity.UsernamePasswordEmailRequest{
+			Username: strings.TrimSpace(field(record, columns["username"])),
+			Password: field(record, columns["password"]),
+			Email:    strings.TrimSpace(field(record, columns["email"])),
+		}
+
+		rows = append(rows, row)
+	}
+}
+
+// ParseNDJSON reads one JSON object per line, blank lines are ignored.
+func ParseNDJSON(r io.Reader) (rows []entity.ImportRow, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if len(rows) == MaxRows {
+			return nil, fmt.Errorf("%w: limit is %d", ErrTooManyRows, MaxRows)
+		}
+
+		row := entity.ImportRow{Line: line}
+
+		if err = json.Unmarshal([]byte(text), &row.User); err != nil {
+			row.Err = "malformed json: " + err.Error()
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ndjson: %w", err)
+	}
+
+	return rows, nil
+}
+
+func field(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+
+	return record[i]
+}
+
+// lineLimitReader fails with ErrLineTooLong once a line goes beyond limit
+// bytes, so a field without end cannot fill the memory.
+type lineLimitReader struct {
+	reader io.Reader
+	limit  int
+	line   int
+}
+
+func (l *lineLimitReader) Read(p []byte) (n int, err error) {
+	n, err = l.reader.Read(p)
+
+	for _, b := range p[:n] {
+		if l.line++; b == '\n' {
+			l.line = 0
+		}
+
+		if l.line > l.limit {
+			return 0, fmt.Errorf("%w: limit is %d bytes", ErrLineTooLong, l.limit)
+		}
+	}
+
+	return n, err //nolint:wrapcheck
+}
@@ -0,0 +1,108 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/importer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatFromContentType(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name      string
+		in        string
+		outFormat string
+		outErr    error
+	}{
+		{name: "CSV", in: "text/csv; charset=utf-8", outFormat: importer.FormatCSV},
+		{name: "NDJSON", in: "application/x-ndjson", outFormat: importer.FormatNDJSON},
+		{name: "ErrorJSON", in: "application/json", outErr: importer.ErrUnknownFormat},
+		{name: "ErrorEmpty", in: "", outErr: importer.ErrUnknownFormat},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			format, err := importer.FormatFromContentType(tt.in)
+
+			assert.ErrorIs(t, err, tt.outErr)
+			assert.Equal(t, tt.outFormat, format)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	user := entity.UsernamePasswordEmailRequest{
+		Username: mock.UsernameTest,
+		Password: mock.PasswordTest,
+		Email:    mock.EmailTest,
+	}
+
+	for _, tt := range []struct {
+		name     string
+		inFormat string
+		in       string
+		out      []entity.ImportRow
+		outErr   error
+	}{
+		{
+			name:     mock.NameNoError + "CSV",
+			inFormat: importer.FormatCSV,
+			in:       "email,username,password\nemail@email.com, username ,password\n\"bad,x\n",
+			out: []entity.ImportRow{
+				{Line: 2, User: user},
+				{Line: 3, Err: "extraneous or missing \" in quoted-field"},
+			},
+		},
+		{
+			name:     mock.NameNoError + "NDJSON",
+			inFormat: importer.FormatNDJSON,
+			in:       `{"username":"username","password":"password","email":"email@email.com"}` + "\n\n{bad\n",
+			out: []entity.ImportRow{
+				{Line: 1, User: user},
+				{Line: 3, Err: "malformed json: invalid character 'b' looking for beginning of object key string"},
+			},
+		},
+		{
+			name:     "ErrorMissingColumn",
+			inFormat: importer.FormatCSV,
+			in:       "username,password\nusername,password\n",
+			outErr:   importer.ErrMissingColumn,
+		},
+		{
+			name:     "ErrorFormat",
+			inFormat: "xml",
+			outErr:   importer.ErrUnknownFormat,
+		},
+		{
+			name:     "ErrorLineTooLong",
+			inFormat: importer.FormatCSV,
+			in:       "username,password,email\n\"" + strings.Repeat("a", 64*1024),
+			outErr:   importer.ErrLineTooLong,
+		},
+		{
+			name:     "ErrorTooManyRows",
+			inFormat: importer.FormatNDJSON,
+			in:       strings.Repeat("{}\n", importer.MaxRows+1),
+			outErr:   importer.ErrTooManyRows,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rows, err := importer.Parse(tt.inFormat, strings.NewReader(tt.in))
+
+			assert.ErrorIs(t, err, tt.outErr)
+			assert.Equal(t, tt.out, rows)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"app/internal/backend"
+	"app/internal/entity"
+)
+
+// DefaultMaxLogins is the number of attempts Logins keeps for each user by
+// default.
+const DefaultMaxLogins int = 1000
+
+// Logins is a backend.LoginHistory keeping the last attempts of each user in
+// memory, so failed attempts cannot grow it without bound. The older ones are
+// lost, as is everything when the process stops.
+type Logins struct {
+	attempts map[int][]entity.LoginAttempt
+	limit    int
+	mutex    sync.RWMutex
+}
+
+var _ backend.LoginHistory = (*Logins)(nil)
+
+// NewLogins returns an empty Logins keeping limit attempts for each user, 0
+// for DefaultMaxLogins.
+func NewLogins(limit int) *Logins {
+	if limit <= 0 {
+		limit = DefaultMaxLogins
+	}
+
+	return &Logins{attempts: make(map[int][]entity.LoginAttempt), limit: limit}
+}
+
+// AppendLogin adds attempt to the history of its user, dropping the oldest
+// one beyond the limit.
+func (l *Logins) AppendLogin(_ context.Context, attempt entity.LoginAttempt) (err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	id := attempt.UserID
+
+	l.attempts[id] = append(l.attempts[id], attempt)
+	if n := len(l.attempts[id]); n > l.limit {
+		l.attempts[id] = append([]entity.LoginAttempt(nil), l.attempts[id][n-l.limit:]...)
+	}
+
+	return nil
+}
+
+// ListLogins returns the attempts of the user, the last one first.
+func (l *Logins) ListLogins(_ context.Context, userID int) (attempts []entity.LoginAttempt, err error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	kept := l.attempts[userID]
+	attempts = make([]entity.LoginAttempt, len(kept))
+
+	for i, attempt := range kept {
+		attempts[len(kept)-1-i] = attempt
+	}
+
+	return attempts, nil
+}
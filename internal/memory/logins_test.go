@@ -0,0 +1,36 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogins(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logins := memory.NewLogins(2)
+
+	for _, attempt := range []entity.LoginAttempt{
+		{UserID: mock.IDTest, IP: "1"},
+		{UserID: 2, IP: "2"},
+		{UserID: mock.IDTest, IP: "3"},
+		{UserID: mock.IDTest, IP: "4"},
+	} {
+		assert.Nil(t, logins.AppendLogin(ctx, attempt))
+	}
+
+	attempts, err := logins.ListLogins(ctx, mock.IDTest)
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.LoginAttempt{{UserID: mock.IDTest, IP: "4"}, {UserID: mock.IDTest, IP: "3"}}, attempts)
+
+	attempts, err = logins.ListLogins(ctx, 3)
+	assert.Nil(t, err)
+	assert.Empty(t, attempts)
+}
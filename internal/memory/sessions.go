@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"app/internal/backend"
+	"app/internal/entity"
+)
+
+var errSessionNotFound = errors.New("session not found")
+
+// DefaultMaxSessions is the number of sessions Sessions keeps by default.
+const DefaultMaxSessions int = 100000
+
+// Sessions is a backend.SessionStore keeping the sessions in memory, they
+// are lost when the process stops. The store does not know when tokens
+// expire, so beyond the limit the least recently used sessions are dropped;
+// their tokens stay valid but are no longer listed.
+type Sessions struct {
+	sessions map[string]*list.Element
+	ids      map[string]string
+	order    *list.List
+	limit    int
+	mutex    sync.RWMutex
+}
+
+var _ backend.SessionStore = (*Sessions)(nil)
+
+// NewSessions returns an empty Sessions keeping limit sessions, 0 for
+// DefaultMaxSessions.
+func NewSessions(limit int) *Sessions {
+	if limit <= 0 {
+		limit = DefaultMaxSessions
+	}
+
+	return &Sessions{
+		sessions: make(map[string]*list.Element),
+		ids:      make(map[string]string),
+		order:    list.New(),
+		limit:    limit,
+	}
+}
+
+// CreateSession keeps session by its token and ID, dropping the least
+// recently used one beyond the limit.
+func (s *Sessions) CreateSession(_ context.Context, session entity.Session) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if element, ok := s.sessions[session.Token]; ok {
+		s.remove(element)
+	}
+
+	s.sessions[session.Token] = s.order.PushFront(session)
+	s.ids[session.ID] = session.Token
+
+	for s.order.Len() > s.limit {
+		s.remove(s.order.Back())
+	}
+
+	return nil
+}
+
+// TouchSession sets the last use of the session of token.
+func (s *Sessions) TouchSession(_ context.Context, token string, at time.Time) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.sessions[token]
+	if !ok {
+		return responseError(errSessionNotFound)
+	}
+
+	session, _ := element.Value.(entity.Session)
+	session.LastSeen = at
+	element.Value = session
+	s.order.MoveToFront(element)
+
+	return nil
+}
+
+// ListSessions returns the sessions of the user, the last used first.
+func (s *Sessions) ListSessions(_ context.Context, userID int) (sessions []entity.Session, err error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sessions = []entity.Session{}
+
+	for _, element := range s.sessions {
+		if session, _ := element.Value.(entity.Session); session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastSeen.Equal(sessions[j].LastSeen) {
+			return sessions[i].LastSeen.After(sessions[j].LastSeen)
+		}
+
+		return sessions[i].ID < sessions[j].ID
+	})
+
+	return sessions, nil
+}
+
+// GetSession returns the session with id.
+func (s *Sessions) GetSession(_ context.Context, id string) (session entity.Session, err error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	element, ok := s.sessions[s.ids[id]]
+	if !ok {
+		return entity.Session{}, responseError(errSessionNotFound)
+	}
+
+	session, _ = element.Value.(entity.Session)
+
+	return session, nil
+}
+
+// DeleteSession forgets the session of token.
+func (s *Sessions) DeleteSession(_ context.Context, token string) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	element, ok := s.sessions[token]
+	if !ok {
+		return responseError(errSessionNotFound)
+	}
+
+	s.remove(element)
+
+	return nil
+}
+
+// DeleteUserSessions forgets every session of the user.
+func (s *Sessions) DeleteUserSessions(_ context.Context, userID int) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, element := range s.sessions {
+		if session, _ := element.Value.(entity.Session); session.UserID == userID {
+			s.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// remove forgets the session of element. The mutex must be held.
+func (s *Sessions) remove(element *list.Element) {
+	session, _ := element.Value.(entity.Session)
+
+	s.order.Remove(element)
+	delete(s.sessions, session.Token)
+	delete(s.ids, session.ID)
+}
@@ -0,0 +1,80 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app/internal/backend"
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sessions := memory.NewSessions(0)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, session := range []entity.Session{
+		{ID: "a", Token: "token-a", UserID: mock.IDTest, LastSeen: start},
+		{ID: "b", Token: "token-b", UserID: mock.IDTest, LastSeen: start},
+		{ID: "c", Token: "token-c", UserID: 2, LastSeen: start},
+	} {
+		assert.Nil(t, sessions.CreateSession(ctx, session))
+	}
+
+	assert.Nil(t, sessions.TouchSession(ctx, "token-b", start.Add(time.Minute)))
+	assert.ErrorIs(t, sessions.TouchSession(ctx, "other", start), backend.ErrResponse)
+
+	list, err := sessions.ListSessions(ctx, mock.IDTest)
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.Session{
+		{ID: "b", Token: "token-b", UserID: mock.IDTest, LastSeen: start.Add(time.Minute)},
+		{ID: "a", Token: "token-a", UserID: mock.IDTest, LastSeen: start},
+	}, list)
+
+	session, err := sessions.GetSession(ctx, "c")
+	assert.Nil(t, err)
+	assert.Equal(t, "token-c", session.Token)
+
+	assert.Nil(t, sessions.DeleteSession(ctx, "token-c"))
+	assert.ErrorIs(t, sessions.DeleteSession(ctx, "token-c"), backend.ErrResponse)
+
+	_, err = sessions.GetSession(ctx, "c")
+	assert.ErrorIs(t, err, backend.ErrResponse)
+
+	assert.Nil(t, sessions.DeleteUserSessions(ctx, mock.IDTest))
+
+	list, err = sessions.ListSessions(ctx, mock.IDTest)
+	assert.Nil(t, err)
+	assert.Empty(t, list)
+}
+
+func TestSessionsLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sessions := memory.NewSessions(2)
+
+	for _, session := range []entity.Session{
+		{ID: "a", Token: "token-a", UserID: mock.IDTest},
+		{ID: "b", Token: "token-b", UserID: mock.IDTest},
+	} {
+		assert.Nil(t, sessions.CreateSession(ctx, session))
+	}
+
+	assert.Nil(t, sessions.TouchSession(ctx, "token-a", time.Now()))
+	assert.Nil(t, sessions.CreateSession(ctx, entity.Session{ID: "c", Token: "token-c", UserID: mock.IDTest}))
+
+	_, err := sessions.GetSession(ctx, "b")
+	assert.ErrorIs(t, err, backend.ErrResponse)
+
+	list, err := sessions.ListSessions(ctx, mock.IDTest)
+	assert.Nil(t, err)
+	assert.Len(t, list, 2)
+}
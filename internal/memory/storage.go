@@ -0,0 +1,184 @@
+// Package memory keeps users, tokens and sessions in the gateway process, in
+// place of the storage and token services, for development and tests.
+package memory
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sort"
+	"sync"
+
+	"app/internal/backend"
+	"app/internal/entity"
+)
+
+var (
+	errUserNotFound  = errors.New("user not found")
+	errUsernameTaken = errors.New("username already exists")
+	errCredentials   = errors.New("invalid username or password")
+)
+
+// Storage is a backend.StorageClient keeping the users in memory. Like the
+// storage service, it does not page and leaves that to the gateway.
+type Storage struct {
+	users     map[int]entity.User
+	usernames map[string]int
+	lastID    int
+	mutex     sync.RWMutex
+}
+
+var _ backend.StorageClient = (*Storage)(nil)
+
+// NewStorage returns an empty Storage.
+func NewStorage() *Storage {
+	return &Storage{
+		users:     make(map[int]entity.User),
+		usernames: make(map[string]int),
+	}
+}
+
+// CreateUser stores user with the next ID.
+func (s *Storage) CreateUser(_ context.Context, user entity.UsernamePasswordEmailRequest) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.usernames[user.Username]; ok {
+		return responseError(errUsernameTaken)
+	}
+
+	s.lastID++
+	s.users[s.lastID] = entity.User{
+		ID:       s.lastID,
+		Username: user.Username,
+		Password: user.Password,
+		Email:    user.Email,
+		Role:     entity.RoleUser,
+	}
+	s.usernames[user.Username] = s.lastID
+
+	return nil
+}
+
+// GetIDByUsername ...
+func (s *Storage) GetIDByUsername(_ context.Context, username string) (id int, err error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.usernames[username], nil
+}
+
+// GetUserByCredentials ...
+func (s *Storage) GetUserByCredentials(_ context.Context, username, password string) (user entity.User, err error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, ok := s.users[s.usernames[username]]
+	if !ok || subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) != 1 {
+		return entity.User{}, responseError(errCredentials)
+	}
+
+	return user, nil
+}
+
+// GetUserByID ...
+func (s *Storage) GetUserByID(_ context.Context, id int) (user entity.User, err error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return entity.User{}, responseError(errUserNotFound)
+	}
+
+	return user, nil
+}
+
+// UpdatePassword ...
+func (s *Storage) UpdatePassword(_ context.Context, id int, password string) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return responseError(errUserNotFound)
+	}
+
+	user.Password = password
+	s.users[id] = user
+
+	return nil
+}
+
+// SetDisabled ...
+func (s *Storage) SetDisabled(_ context.Context, id int, disabled bool) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return responseError(errUserNotFound)
+	}
+
+	user.Disabled = disabled
+	s.users[id] = user
+
+	return nil
+}
+
+// SetRole ...
+func (s *Storage) SetRole(_ context.Context, id int, role string) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return responseError(errUserNotFound)
+	}
+
+	user.Role = role
+	s.users[id] = user
+
+	return nil
+}
+
+// DeleteUser ...
+func (s *Storage) DeleteUser(_ context.Context, id int) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return responseError(errUserNotFound)
+	}
+
+	delete(s.users, id)
+	delete(s.usernames, user.Username)
+
+	return nil
+}
+
+// ListUsers returns every user ordered by ID, whatever the options.
+func (s *Storage) ListUsers(
+	_ context.Context,
+	_ entity.ListUsersOptions,
+) (page entity.UsersPage, paged bool, err error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	page.Users = make([]entity.User, 0, len(s.users))
+	for _, user := range s.users {
+		page.Users = append(page.Users, user)
+	}
+
+	sort.Slice(page.Users, func(i, j int) bool {
+		return page.Users[i].ID < page.Users[j].ID
+	})
+
+	return page, false, nil
+}
+
+// responseError reports err like a backend answering it.
+func responseError(err error) error {
+	return &backend.ResponseError{Message: err.Error()}
+}
@@ -0,0 +1,107 @@
+package memory_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"app/internal/backend"
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/memory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewStorage()
+	user := entity.UsernamePasswordEmailRequest{
+		Username: mock.UsernameTest,
+		Password: mock.PasswordTest,
+		Email:    mock.EmailTest,
+	}
+
+	assert.Nil(t, storage.CreateUser(ctx, user))
+	assert.ErrorIs(t, storage.CreateUser(ctx, user), backend.ErrResponse)
+
+	id, err := storage.GetIDByUsername(ctx, mock.UsernameTest)
+	assert.Nil(t, err)
+	assert.Equal(t, mock.IDTest, id)
+
+	id, err = storage.GetIDByUsername(ctx, "missing")
+	assert.Nil(t, err)
+	assert.Zero(t, id)
+
+	got, err := storage.GetUserByCredentials(ctx, mock.UsernameTest, mock.PasswordTest)
+	assert.Nil(t, err)
+	assert.Equal(t, mock.EmailTest, got.Email)
+
+	_, err = storage.GetUserByCredentials(ctx, mock.UsernameTest, "wrong")
+	assert.ErrorIs(t, err, backend.ErrResponse)
+
+	assert.Nil(t, storage.UpdatePassword(ctx, mock.IDTest, "hash"))
+
+	got, err = storage.GetUserByID(ctx, mock.IDTest)
+	assert.Nil(t, err)
+	assert.Equal(t, "hash", got.Password)
+
+	assert.Nil(t, storage.SetDisabled(ctx, mock.IDTest, true))
+	assert.Nil(t, storage.SetRole(ctx, mock.IDTest, entity.RoleAdmin))
+
+	got, err = storage.GetUserByID(ctx, mock.IDTest)
+	assert.Nil(t, err)
+	assert.True(t, got.Disabled)
+	assert.Equal(t, entity.RoleAdmin, got.Role)
+
+	assert.Nil(t, storage.CreateUser(ctx, entity.UsernamePasswordEmailRequest{Username: "other"}))
+
+	page, paged, err := storage.ListUsers(ctx, entity.ListUsersOptions{Limit: 1})
+	assert.Nil(t, err)
+	assert.False(t, paged)
+	assert.Equal(t, []string{mock.UsernameTest, "other"}, []string{page.Users[0].Username, page.Users[1].Username})
+
+	assert.Nil(t, storage.DeleteUser(ctx, mock.IDTest))
+	assert.ErrorIs(t, storage.DeleteUser(ctx, mock.IDTest), backend.ErrResponse)
+	assert.ErrorIs(t, storage.UpdatePassword(ctx, mock.IDTest, "hash"), backend.ErrResponse)
+	assert.ErrorIs(t, storage.SetDisabled(ctx, mock.IDTest, true), backend.ErrResponse)
+	assert.ErrorIs(t, storage.SetRole(ctx, mock.IDTest, entity.RoleAdmin), backend.ErrResponse)
+
+	_, err = storage.GetUserByID(ctx, mock.IDTest)
+	assert.EqualError(t, err, "user not found")
+
+	// The username is free again, under a new ID.
+	assert.Nil(t, storage.CreateUser(ctx, user))
+
+	id, err = storage.GetIDByUsername(ctx, mock.UsernameTest)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, id)
+}
+
+func TestStorageConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := memory.NewStorage()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			_ = storage.CreateUser(ctx, entity.UsernamePasswordEmailRequest{Username: string(rune('a' + i%26))})
+			_, _, _ = storage.ListUsers(ctx, entity.ListUsersOptions{})
+		}(i)
+	}
+
+	wg.Wait()
+
+	page, _, err := storage.ListUsers(ctx, entity.ListUsersOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, page.Users, 26)
+}
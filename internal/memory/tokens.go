@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"app/internal/backend"
+	"app/internal/claims"
+	"app/internal/entity"
+)
+
+const tokenIDBytes int = 16
+
+// DefaultMaxTokens is the number of tokens Tokens keeps by default.
+const DefaultMaxTokens int = 100000
+
+// Tokens is a backend.TokenClient issuing HS256 JWTs signed with the secret
+// of each call, and keeping the stored ones in an allow-list. The tokens do
+// not expire, so beyond the limit the oldest ones are dropped, which logs
+// them out.
+type Tokens struct {
+	now    func() time.Time
+	tokens map[string]*list.Element
+	order  *list.List
+	limit  int
+	mutex  sync.RWMutex
+}
+
+// issuedToken is a token generated by Tokens, in the order of issue.
+type issuedToken struct {
+	token  string
+	owner  int
+	stored bool
+}
+
+var _ backend.TokenClient = (*Tokens)(nil)
+
+// NewTokens returns Tokens reading the time from now, nil for time.Now, and
+// keeping limit tokens, 0 for DefaultMaxTokens.
+func NewTokens(now func() time.Time, limit int) *Tokens {
+	if now == nil {
+		now = time.Now
+	}
+
+	if limit <= 0 {
+		limit = DefaultMaxTokens
+	}
+
+	return &Tokens{now: now, tokens: make(map[string]*list.Element), order: list.New(), limit: limit}
+}
+
+// Generate signs a token for claims. Each token has its own ID, so two
+// tokens of the same user are revoked apart.
+func (t *Tokens) Generate(_ context.Context, user entity.Claims, secret string) (token string, err error) {
+	id := make([]byte, tokenIDBytes)
+	if _, err = rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
+	if token, err = claims.Sign([]byte(secret), user, hex.EncodeToString(id), t.now()); err != nil {
+		return "", responseError(err)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.add(&issuedToken{token: token, owner: user.ID})
+
+	return token, nil
+}
+
+// Store adds token to the allow-list.
+func (t *Tokens) Store(_ context.Context, token string) (err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if element, ok := t.tokens[token]; ok {
+		issued, _ := element.Value.(*issuedToken)
+		issued.stored = true
+
+		return nil
+	}
+
+	t.add(&issuedToken{token: token, stored: true})
+
+	return nil
+}
+
+// Check reports whether token is in the allow-list.
+func (t *Tokens) Check(_ context.Context, token string) (check bool, err error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if element, ok := t.tokens[token]; ok {
+		issued, _ := element.Value.(*issuedToken)
+		check = issued.stored
+	}
+
+	return check, nil
+}
+
+// Extract returns the claims of token after checking its signature with
+// secret.
+func (t *Tokens) Extract(_ context.Context, token, secret string) (user entity.Claims, err error) {
+	verifier, err := claims.NewVerifier([]byte(secret), claims.VerifierConfig{}, t.now)
+	if err != nil {
+		return entity.Claims{}, responseError(err)
+	}
+
+	if user, err = verifier.Verify(token); err != nil {
+		return entity.Claims{}, responseError(err)
+	}
+
+	return user, nil
+}
+
+// Revoke removes token from the allow-list.
+func (t *Tokens) Revoke(_ context.Context, token string) (err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if element, ok := t.tokens[token]; ok {
+		t.remove(element)
+	}
+
+	return nil
+}
+
+// RevokeUser removes the tokens generated for the user id from the
+// allow-list.
+func (t *Tokens) RevokeUser(_ context.Context, id int) (err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for element := t.order.Front(); element != nil; {
+		next := element.Next()
+
+		if issued, _ := element.Value.(*issuedToken); issued.owner == id {
+			t.remove(element)
+		}
+
+		element = next
+	}
+
+	return nil
+}
+
+// add keeps issued, dropping the oldest tokens beyond the limit.
+func (t *Tokens) add(issued *issuedToken) {
+	t.tokens[issued.token] = t.order.PushBack(issued)
+
+	for t.order.Len() > t.limit {
+		t.remove(t.order.Front())
+	}
+}
+
+func (t *Tokens) remove(element *list.Element) {
+	issued, _ := t.order.Remove(element).(*issuedToken)
+	delete(t.tokens, issued.token)
+}
@@ -0,0 +1,217 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app/internal/backend"
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/memory"
+	"app/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokens(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tokens := memory.NewTokens(nil, 0)
+	claims := entity.Claims{ID: mock.IDTest, Username: mock.UsernameTest, Email: mock.EmailTest, Role: entity.RoleUser}
+
+	first, err := tokens.Generate(ctx, claims, mock.SecretTest)
+	assert.Nil(t, err)
+
+	second, err := tokens.Generate(ctx, claims, mock.SecretTest)
+	assert.Nil(t, err)
+	assert.NotEqual(t, first, second)
+
+	for _, tt := range []struct {
+		name     string
+		inToken  string
+		inSecret string
+		outErr   bool
+	}{
+		{
+			name:     mock.NameNoError,
+			inToken:  first,
+			inSecret: mock.SecretTest,
+		},
+		{
+			name:     "ErrorSecret",
+			inToken:  first,
+			inSecret: "other",
+			outErr:   true,
+		},
+		{
+			name:     "ErrorMalformed",
+			inToken:  "token",
+			inSecret: mock.SecretTest,
+			outErr:   true,
+		},
+		{
+			// An unsigned token, alg "none".
+			name:     "ErrorAlgorithm",
+			inToken:  "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJpZCI6MX0.",
+			inSecret: mock.SecretTest,
+			outErr:   true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tokens.Extract(ctx, tt.inToken, tt.inSecret)
+			if tt.outErr {
+				assert.ErrorIs(t, err, backend.ErrResponse)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, claims, got)
+		})
+	}
+}
+
+func TestTokensAllowList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tokens := memory.NewTokens(func() time.Time { return time.Unix(0, 0) }, 0)
+
+	token, err := tokens.Generate(ctx, entity.Claims{ID: mock.IDTest}, mock.SecretTest)
+	assert.Nil(t, err)
+
+	check, err := tokens.Check(ctx, token)
+	assert.Nil(t, err)
+	assert.False(t, check)
+
+	assert.Nil(t, tokens.Store(ctx, token))
+
+	check, err = tokens.Check(ctx, token)
+	assert.Nil(t, err)
+	assert.True(t, check)
+
+	assert.Nil(t, tokens.Revoke(ctx, token))
+
+	check, err = tokens.Check(ctx, token)
+	assert.Nil(t, err)
+	assert.False(t, check)
+
+	first, err := tokens.Generate(ctx, entity.Claims{ID: mock.IDTest}, mock.SecretTest)
+	assert.Nil(t, err)
+
+	second, err := tokens.Generate(ctx, entity.Claims{ID: mock.IDTest}, mock.SecretTest)
+	assert.Nil(t, err)
+
+	other, err := tokens.Generate(ctx, entity.Claims{ID: 2}, mock.SecretTest)
+	assert.Nil(t, err)
+
+	for _, token := range []string{first, second, other} {
+		assert.Nil(t, tokens.Store(ctx, token))
+	}
+
+	assert.Nil(t, tokens.RevokeUser(ctx, mock.IDTest))
+
+	for token, valid := range map[string]bool{first: false, second: false, other: true} {
+		check, err = tokens.Check(ctx, token)
+		assert.Nil(t, err)
+		assert.Equal(t, valid, check)
+	}
+}
+
+func TestTokensLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tokens := memory.NewTokens(nil, 2)
+	issued := make([]string, 3)
+
+	for i := range issued {
+		token, err := tokens.Generate(ctx, entity.Claims{ID: mock.IDTest}, mock.SecretTest)
+		assert.Nil(t, err)
+		assert.Nil(t, tokens.Store(ctx, token))
+
+		issued[i] = token
+	}
+
+	// The oldest token is dropped.
+	for i, valid := range []bool{false, true, true} {
+		check, err := tokens.Check(ctx, issued[i])
+		assert.Nil(t, err)
+		assert.Equal(t, valid, check)
+	}
+}
+
+func TestServiceEmbedded(t *testing.T) {
+	t.Parallel()
+
+	svc := service.NewServiceWithBackends(memory.NewStorage(), memory.NewTokens(nil, 0), &service.InfoServices{
+		Secret: mock.SecretTest,
+	})
+
+	token, err := svc.SignUp(mock.UsernameTest, mock.PasswordTest, mock.EmailTest, entity.Client{})
+	assert.Nil(t, err)
+
+	user, err := svc.Profile(token)
+	assert.Nil(t, err)
+	assert.Equal(t, mock.UsernameTest, user.Username)
+	assert.Equal(t, entity.RoleUser, user.Role)
+	assert.NotEqual(t, mock.PasswordTest, user.Password)
+
+	_, err = svc.SignUp(mock.UsernameTest, mock.PasswordTest, mock.EmailTest, entity.Client{})
+	assert.ErrorIs(t, err, service.ErrWebServer)
+
+	_, err = svc.SignIn(mock.UsernameTest, "wrong", entity.Client{})
+	assert.ErrorIs(t, err, service.ErrCredentials)
+
+	assert.Nil(t, svc.ChangePassword(token, mock.PasswordTest, "new password"))
+
+	signedIn, err := svc.SignIn(mock.UsernameTest, "new password", entity.Client{})
+	assert.Nil(t, err)
+
+	assert.Nil(t, svc.LogOut(token))
+	assert.ErrorIs(t, svc.LogOut(token), service.ErrTokenNotValid)
+
+	adminToken, err := svc.SignUp("admin", mock.PasswordTest, "admin@email.com", entity.Client{})
+	assert.Nil(t, err)
+
+	// Usernames give no role, admins are promoted by ID.
+	_, err = svc.Authorize(adminToken, entity.RoleAdmin)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	admin, err := svc.Profile(adminToken)
+	assert.Nil(t, err)
+	assert.Nil(t, svc.PromoteAdmins([]int{admin.ID}))
+
+	_, err = svc.Authorize(adminToken, entity.RoleAdmin)
+	assert.Nil(t, err)
+
+	users, err := svc.ListUsers(entity.ListUsersOptions{Limit: 1})
+	assert.Nil(t, err)
+	assert.Len(t, users.Users, 1)
+	assert.NotEmpty(t, users.NextCursor)
+
+	other, err := svc.SignIn(mock.UsernameTest, "new password", entity.Client{})
+	assert.Nil(t, err)
+
+	assert.Nil(t, svc.LogOutAll(other))
+	assert.ErrorIs(t, svc.LogOut(signedIn), service.ErrTokenNotValid)
+
+	signedIn, err = svc.SignIn(mock.UsernameTest, "new password", entity.Client{})
+	assert.Nil(t, err)
+
+	other, err = svc.SignIn(mock.UsernameTest, "new password", entity.Client{})
+	assert.Nil(t, err)
+
+	assert.Nil(t, svc.DeleteAccount(signedIn))
+
+	_, err = svc.Profile(other)
+	assert.ErrorIs(t, err, service.ErrTokenNotValid)
+
+	_, err = svc.SignIn(mock.UsernameTest, "new password", entity.Client{})
+	assert.ErrorIs(t, err, service.ErrCredentials)
+}
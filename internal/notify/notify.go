@@ -0,0 +1,99 @@
+// Package notify tells users about sign-ins from devices they did not use
+// before.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"app/internal/entity"
+)
+
+// ErrWebhook is returned when the webhook answers with an error status.
+var ErrWebhook = errors.New("webhook failed")
+
+// Notifier sends the notice of a sign-in of user from a new device.
+type Notifier interface {
+	NotifyNewDevice(ctx context.Context, user entity.User, attempt entity.LoginAttempt) error
+}
+
+// Log is a Notifier writing the notices to a log, for development or to be
+// picked up by a log pipeline.
+type Log struct {
+	logger *log.Logger
+}
+
+// NewLog returns a Log writing to logger, nil for the standard logger.
+func NewLog(logger *log.Logger) *Log {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &Log{logger: logger}
+}
+
+// NotifyNewDevice logs the notice.
+func (l *Log) NotifyNewDevice(_ context.Context, user entity.User, attempt entity.LoginAttempt) (err error) {
+	l.logger.Printf("new device for user %d (%s): %s from %s", user.ID, user.Username, attempt.Device, attempt.IP)
+
+	return nil
+}
+
+// Webhook is a Notifier posting the notices as JSON to a URL, which sends
+// them on to the user.
+type Webhook struct {
+	client *http.Client
+	url    string
+}
+
+// webhookBody is the JSON posted by Webhook.
+type webhookBody struct {
+	User  webhookUser         `json:"user"`
+	Login entity.LoginAttempt `json:"login"`
+}
+
+type webhookUser struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	ID       int    `json:"id"`
+}
+
+// NewWebhook returns a Webhook posting to url with client.
+func NewWebhook(client *http.Client, url string) *Webhook {
+	return &Webhook{client: client, url: url}
+}
+
+// NotifyNewDevice posts the notice, any status but 2xx is an error.
+func (w *Webhook) NotifyNewDevice(ctx context.Context, user entity.User, attempt entity.LoginAttempt) (err error) {
+	body, err := json.Marshal(webhookBody{
+		User:  webhookUser{ID: user.ID, Username: user.Username, Email: user.Email},
+		Login: attempt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode notice: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: status %d", ErrWebhook, resp.StatusCode)
+	}
+
+	return nil
+}
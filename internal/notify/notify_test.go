@@ -0,0 +1,81 @@
+package notify_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+
+	notifier := notify.NewLog(log.New(&buffer, "", 0))
+
+	assert.Nil(t, notifier.NotifyNewDevice(
+		context.Background(),
+		entity.User{ID: mock.IDTest, Username: mock.UsernameTest},
+		entity.LoginAttempt{Device: "Firefox on Linux", IP: "192.0.2.1"},
+	))
+	assert.Equal(t, "new device for user 1 (username): Firefox on Linux from 192.0.2.1\n", buffer.String())
+}
+
+func TestWebhook(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name     string
+		inStatus int
+		outErr   error
+	}{
+		{
+			name:     mock.NameNoError,
+			inStatus: http.StatusNoContent,
+		},
+		{
+			name:     "ErrorStatus",
+			inStatus: http.StatusInternalServerError,
+			outErr:   notify.ErrWebhook,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var body []byte
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				body, _ = io.ReadAll(r.Body)
+
+				w.WriteHeader(tt.inStatus)
+			}))
+			defer srv.Close()
+
+			err := notify.NewWebhook(srv.Client(), srv.URL).NotifyNewDevice(
+				context.Background(),
+				entity.User{ID: mock.IDTest, Username: mock.UsernameTest, Email: mock.EmailTest, Password: "hash"},
+				entity.LoginAttempt{Method: entity.LoginMethodPassword, Device: "curl", Success: true},
+			)
+			assert.ErrorIs(t, err, tt.outErr)
+			assert.JSONEq(t, `{
+				"user":{"username":"username","email":"email@email.com","id":1},
+				"login":{"time":"0001-01-01T00:00:00Z","method":"password","ip":"","userAgent":"",
+					"device":"curl","success":true}
+			}`, string(body))
+		})
+	}
+}
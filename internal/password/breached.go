@@ -0,0 +1,88 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec,revive // the breach files are keyed by SHA-1.
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// prefixLength is the number of hex characters of the SHA-1 that name a
+// range file.
+const prefixLength int = 5
+
+// BreachedList looks passwords up in a directory of k-anonymity range files,
+// as served by the Pwned Passwords API: each file is named after the first
+// five hex characters of the SHA-1 of the passwords it holds, with an
+// optional ".txt" extension, and has one "SUFFIX:COUNT" line per password.
+// Only the file of the password's prefix is read, so the full list never has
+// to fit in memory.
+type BreachedList struct {
+	dir string
+}
+
+// NewBreachedList checks dir is a directory.
+func NewBreachedList(dir string) (list *BreachedList, err error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached passwords: %w", err)
+	}
+
+	if !info.IsDir() {
+		return nil, fmt.Errorf("failed to open breached passwords: %s isn't a directory", dir)
+	}
+
+	return &BreachedList{dir: dir}, nil
+}
+
+// Count returns how many times password was seen in a breach, 0 when its
+// range file does not exist.
+func (l *BreachedList) Count(password string) (count int, err error) {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec
+	encoded := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := encoded[:prefixLength], encoded[prefixLength:]
+
+	file, err := os.Open(filepath.Join(l.dir, prefix+".txt"))
+	if errors.Is(err, fs.ErrNotExist) {
+		file, err = os.Open(filepath.Join(l.dir, prefix))
+	}
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to read breached passwords: %w", err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		hash, value, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !strings.EqualFold(hash, suffix) {
+			continue
+		}
+
+		// A line without a count still marks the password as breached, padding
+		// lines of the API have a count of 0 and do not.
+		if count, err = strconv.Atoi(value); err != nil {
+			return 1, nil //nolint:nilerr
+		}
+
+		return count, nil
+	}
+
+	if err = scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read breached passwords: %w", err)
+	}
+
+	return 0, nil
+}
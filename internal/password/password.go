@@ -0,0 +1,336 @@
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Scheme identifiers, the first field of a PHC string.
+const (
+	SchemeArgon2id     string = "argon2id"
+	SchemeBcrypt       string = "2b"
+	SchemeScrypt       string = "scrypt"
+	SchemePBKDF2SHA256 string = "pbkdf2-sha256"
+	SchemePBKDF2SHA512 string = "pbkdf2-sha512"
+
+	maxScryptLog    int    = 20
+	maxArgon2Memory uint32 = 4 * 1024 * 1024
+)
+
+var (
+	ErrUnknownScheme = errors.New("unknown password hash scheme")
+	ErrMalformedHash = errors.New("malformed password hash")
+)
+
+// Params of the Argon2id hashes made by a Hasher, Memory is in KiB.
+type Params struct {
+	Time       uint32
+	Memory     uint32
+	Threads    uint8
+	KeyLength  uint32
+	SaltLength uint32
+}
+
+// Hasher verifies passwords against the supported hash schemes and hashes
+// new ones with the current scheme, Argon2id. When a pepper is set the
+// Argon2id input is the HMAC-SHA256 of the password keyed with it, so
+// changing the pepper invalidates every Argon2id hash.
+type Hasher struct {
+	pepper []byte
+	params Params
+}
+
+// phc is a parsed "$scheme$params$salt$hash" string.
+type phc struct {
+	params map[string]string
+	scheme string
+	salt   []byte
+	hash   []byte
+}
+
+// DefaultParams follows the OWASP recommendation for Argon2id.
+func DefaultParams() Params {
+	return Params{
+		Time:       2,
+		Memory:     19 * 1024,
+		Threads:    1,
+		KeyLength:  32,
+		SaltLength: 16,
+	}
+}
+
+// NewHasher ... Zero fields of params take their default value.
+func NewHasher(params Params, pepper string) *Hasher {
+	defaults := DefaultParams()
+
+	if params.Time == 0 {
+		params.Time = defaults.Time
+	}
+
+	if params.Memory == 0 {
+		params.Memory = defaults.Memory
+	}
+
+	if params.Threads == 0 {
+		params.Threads = defaults.Threads
+	}
+
+	if params.KeyLength == 0 {
+		params.KeyLength = defaults.KeyLength
+	}
+
+	if params.SaltLength == 0 {
+		params.SaltLength = defaults.SaltLength
+	}
+
+	return &Hasher{params: params, pepper: []byte(pepper)}
+}
+
+// IsHash reports whether encoded starts with the prefix of a supported
+// scheme, that is whether it is a hash and not a plain password.
+func IsHash(encoded string) (check bool) {
+	scheme, _, ok := strings.Cut(strings.TrimPrefix(encoded, "$"), "$")
+	if !ok || !strings.HasPrefix(encoded, "$") {
+		return false
+	}
+
+	switch scheme {
+	case SchemeArgon2id, "2a", SchemeBcrypt, "2y", SchemeScrypt, SchemePBKDF2SHA256, SchemePBKDF2SHA512:
+		return true
+	default:
+		return false
+	}
+}
+
+// Hash ...
+func (h *Hasher) Hash(password string) (encoded string, err error) {
+	salt := make([]byte, h.params.SaltLength)
+
+	if _, err = rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey(
+		h.peppered(password),
+		salt,
+		h.params.Time,
+		h.params.Memory,
+		h.params.Threads,
+		h.params.KeyLength,
+	)
+
+	return fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		SchemeArgon2id,
+		argon2.Version,
+		h.params.Memory,
+		h.params.Time,
+		h.params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify checks password against encoded.
+func (h *Hasher) Verify(encoded, password string) (ok bool, err error) {
+	if !IsHash(encoded) {
+		return false, ErrUnknownScheme
+	}
+
+	if strings.HasPrefix(encoded, "$2") {
+		err = bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("%w: %s", ErrMalformedHash, err.Error())
+		}
+
+		return true, nil
+	}
+
+	parsed, err := parsePHC(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	var key []byte
+
+	switch parsed.scheme {
+	case SchemeArgon2id:
+		key, err = parsed.argon2id(h.peppered(password))
+	case SchemeScrypt:
+		key, err = parsed.scrypt([]byte(password))
+	case SchemePBKDF2SHA256:
+		key, err = parsed.pbkdf2([]byte(password), sha256.New)
+	case SchemePBKDF2SHA512:
+		key, err = parsed.pbkdf2([]byte(password), sha512.New)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(key, parsed.hash) == 1, nil
+}
+
+// NeedsRehash reports whether encoded is not an Argon2id hash made with the
+// current parameters.
+func (h *Hasher) NeedsRehash(encoded string) (check bool) {
+	parsed, err := parsePHC(encoded)
+	if err != nil || parsed.scheme != SchemeArgon2id {
+		return true
+	}
+
+	return parsed.params["m"] != strconv.FormatUint(uint64(h.params.Memory), 10) ||
+		parsed.params["t"] != strconv.FormatUint(uint64(h.params.Time), 10) ||
+		parsed.params["p"] != strconv.FormatUint(uint64(h.params.Threads), 10) ||
+		len(parsed.hash) != int(h.params.KeyLength) ||
+		len(parsed.salt) != int(h.params.SaltLength)
+}
+
+func (h *Hasher) peppered(password string) []byte {
+	if len(h.pepper) == 0 {
+		return []byte(password)
+	}
+
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(password))
+
+	return mac.Sum(nil)
+}
+
+func parsePHC(encoded string) (parsed phc, err error) {
+	// "$scheme[$v=version]$params$salt$hash", the leading "$" makes the first
+	// field empty.
+	var version string
+
+	fields := strings.Split(encoded, "$")
+	if len(fields) == 6 && strings.HasPrefix(fields[2], "v=") {
+		version = strings.TrimPrefix(fields[2], "v=")
+		fields = append(fields[:2], fields[3:]...)
+	}
+
+	if len(fields) != 5 || fields[0] != "" {
+		return phc{}, ErrMalformedHash
+	}
+
+	parsed = phc{scheme: fields[1], params: map[string]string{"v": version}}
+
+	for _, param := range strings.Split(fields[2], ",") {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			// passlib writes the PBKDF2 rounds without a name.
+			name, value = "i", param
+		}
+
+		parsed.params[name] = value
+	}
+
+	if parsed.salt, err = decodeB64(fields[3]); err != nil {
+		return phc{}, err
+	}
+
+	if parsed.hash, err = decodeB64(fields[4]); err != nil {
+		return phc{}, err
+	}
+
+	if len(parsed.salt) == 0 || len(parsed.hash) == 0 {
+		return phc{}, ErrMalformedHash
+	}
+
+	return parsed, nil
+}
+
+// decodeB64 accepts the unpadded standard alphabet of the PHC format and the
+// "adapted" one used by passlib, where "+" is written as ".".
+func decodeB64(s string) (data []byte, err error) {
+	s = strings.TrimRight(strings.ReplaceAll(s, ".", "+"), "=")
+
+	if data, err = base64.RawStdEncoding.DecodeString(s); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformedHash, err.Error())
+	}
+
+	return data, nil
+}
+
+func (p phc) int(name string) (value int, err error) {
+	if value, err = strconv.Atoi(p.params[name]); err != nil || value <= 0 {
+		return 0, fmt.Errorf("%w: bad parameter %s", ErrMalformedHash, name)
+	}
+
+	return value, nil
+}
+
+func (p phc) pbkdf2(password []byte, newHash func() hash.Hash) (key []byte, err error) {
+	iterations, err := p.int("i")
+	if err != nil {
+		return nil, err
+	}
+
+	return pbkdf2.Key(password, p.salt, iterations, len(p.hash), newHash), nil
+}
+
+func (p phc) scrypt(password []byte) (key []byte, err error) {
+	values := make(map[string]int, 3)
+
+	for _, name := range []string{"ln", "r", "p"} {
+		if values[name], err = p.int(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if values["ln"] > maxScryptLog {
+		return nil, fmt.Errorf("%w: bad parameter ln", ErrMalformedHash)
+	}
+
+	key, err = scrypt.Key(password, p.salt, 1<<values["ln"], values["r"], values["p"], len(p.hash))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformedHash, err.Error())
+	}
+
+	return key, nil
+}
+
+func (p phc) argon2id(password []byte) (key []byte, err error) {
+	values := make(map[string]int, 3)
+
+	for _, name := range []string{"m", "t", "p"} {
+		if values[name], err = p.int(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.params["v"] != strconv.Itoa(argon2.Version) {
+		return nil, fmt.Errorf("%w: unsupported argon2 version", ErrMalformedHash)
+	}
+
+	if values["m"] > int(maxArgon2Memory) || values["p"] > math.MaxUint8 || values["t"] > math.MaxUint32 {
+		return nil, fmt.Errorf("%w: bad parameters", ErrMalformedHash)
+	}
+
+	return argon2.IDKey(
+		password,
+		p.salt,
+		uint32(values["t"]),
+		uint32(values["m"]),
+		uint8(values["p"]),
+		uint32(len(p.hash)),
+	), nil
+}
@@ -0,0 +1,193 @@
+package password_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"app/internal/entity/mock"
+	"app/internal/password"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+)
+
+const passlibPBKDF2Test string = "$pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M"
+
+func getScryptTest(t *testing.T) string {
+	t.Helper()
+
+	salt := []byte("saltsaltsaltsalt")
+
+	key, err := scrypt.Key([]byte(mock.PasswordTest), salt, 1<<4, 8, 1, 32)
+	assert.Nil(t, err)
+
+	return "$scrypt$ln=4,r=8,p=1$" +
+		base64.RawStdEncoding.EncodeToString(salt) + "$" +
+		base64.RawStdEncoding.EncodeToString(key)
+}
+
+func getBcryptTest(t *testing.T) string {
+	t.Helper()
+
+	encoded, err := bcrypt.GenerateFromPassword([]byte(mock.PasswordTest), bcrypt.MinCost)
+	assert.Nil(t, err)
+
+	return string(encoded)
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	hasher := password.NewHasher(password.Params{Memory: 1024, Time: 1}, "pepper")
+
+	current, err := hasher.Hash(mock.PasswordTest)
+	assert.Nil(t, err)
+
+	unpeppered, err := password.NewHasher(password.Params{Memory: 1024, Time: 1}, "").Hash(mock.PasswordTest)
+	assert.Nil(t, err)
+
+	for _, tt := range []struct {
+		name          string
+		inEncoded     string
+		inPassword    string
+		outErr        error
+		outOK         bool
+		outIsHash     bool
+		outNeedRehash bool
+	}{
+		{
+			name:       "NoErrorCurrent",
+			inEncoded:  current,
+			inPassword: mock.PasswordTest,
+			outOK:      true,
+			outIsHash:  true,
+		},
+		{
+			name:          "NoErrorBcrypt",
+			inEncoded:     getBcryptTest(t),
+			inPassword:    mock.PasswordTest,
+			outOK:         true,
+			outIsHash:     true,
+			outNeedRehash: true,
+		},
+		{
+			name:          "NoErrorScrypt",
+			inEncoded:     getScryptTest(t),
+			inPassword:    mock.PasswordTest,
+			outOK:         true,
+			outIsHash:     true,
+			outNeedRehash: true,
+		},
+		{
+			name:          "NoErrorPasslibPBKDF2",
+			inEncoded:     passlibPBKDF2Test,
+			inPassword:    mock.PasswordTest,
+			outOK:         true,
+			outIsHash:     true,
+			outNeedRehash: true,
+		},
+		{
+			name:       "Mismatch",
+			inEncoded:  current,
+			inPassword: "wrong",
+			outIsHash:  true,
+		},
+		{
+			name:       "MismatchPepper",
+			inEncoded:  unpeppered,
+			inPassword: mock.PasswordTest,
+			outIsHash:  true,
+		},
+		{
+			name:          "MismatchBcrypt",
+			inEncoded:     getBcryptTest(t),
+			inPassword:    "wrong",
+			outIsHash:     true,
+			outNeedRehash: true,
+		},
+		{
+			name:          "ErrorPlain",
+			inEncoded:     mock.PasswordTest,
+			inPassword:    mock.PasswordTest,
+			outErr:        password.ErrUnknownScheme,
+			outNeedRehash: true,
+		},
+		{
+			name:          "ErrorEmptyHash",
+			inEncoded:     "$pbkdf2-sha256$i=1000$c2FsdA$",
+			inPassword:    mock.PasswordTest,
+			outErr:        password.ErrMalformedHash,
+			outIsHash:     true,
+			outNeedRehash: true,
+		},
+		{
+			name:          "ErrorArgon2Version",
+			inEncoded:     "$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$c2FsdA",
+			inPassword:    mock.PasswordTest,
+			outErr:        password.ErrMalformedHash,
+			outIsHash:     true,
+			outNeedRehash: true,
+		},
+		{
+			name:          "ErrorScryptParameter",
+			inEncoded:     "$scrypt$ln=64,r=8,p=1$c2FsdA$c2FsdA",
+			inPassword:    mock.PasswordTest,
+			outErr:        password.ErrMalformedHash,
+			outIsHash:     true,
+			outNeedRehash: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ok, err := hasher.Verify(tt.inEncoded, tt.inPassword)
+
+			assert.ErrorIs(t, err, tt.outErr)
+			assert.Equal(t, tt.outOK, ok)
+			assert.Equal(t, tt.outIsHash, password.IsHash(tt.inEncoded))
+			assert.Equal(t, tt.outNeedRehash, hasher.NeedsRehash(tt.inEncoded))
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	t.Parallel()
+
+	weak, err := password.NewHasher(password.Params{Memory: 1024, Time: 1}, "").Hash(mock.PasswordTest)
+	assert.Nil(t, err)
+
+	for _, tt := range []struct {
+		name     string
+		inParams password.Params
+		out      bool
+	}{
+		{
+			name:     "Same",
+			inParams: password.Params{Memory: 1024, Time: 1},
+		},
+		{
+			name:     "Memory",
+			inParams: password.Params{Memory: 2048, Time: 1},
+			out:      true,
+		},
+		{
+			name:     "Time",
+			inParams: password.Params{Memory: 1024, Time: 2},
+			out:      true,
+		},
+		{
+			name:     "KeyLength",
+			inParams: password.Params{Memory: 1024, Time: 1, KeyLength: 64},
+			out:      true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.out, password.NewHasher(tt.inParams, "").NeedsRehash(weak))
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// Character classes of a password, Entropy sizes their pools.
+const (
+	classLower int = iota
+	classUpper
+	classDigit
+	classSymbol
+	classOther
+
+	// minPersonalLength is the shortest username or email part looked for
+	// inside a password, shorter ones match too many passwords by chance.
+	minPersonalLength int = 3
+)
+
+var ErrWeakPassword = errors.New("password does not meet the policy")
+
+// Policy a new password must meet. Zero fields disable their check.
+type Policy struct {
+	// Breached rejects passwords found in the list.
+	Breached *BreachedList
+	// MinEntropy is in bits, as estimated by Entropy.
+	MinEntropy float64
+	MinLength  int
+	MaxLength  int
+	// MinClasses is the number of classes, among lowercase, uppercase,
+	// digits and symbols, the password must use.
+	MinClasses int
+}
+
+// PolicyError lists every rule a password broke.
+type PolicyError struct {
+	Violations []string
+}
+
+// DefaultPolicy follows NIST SP 800-63B: length over composition rules.
+func DefaultPolicy() Policy {
+	return Policy{
+		MinLength:  8,
+		MaxLength:  128,
+		MinEntropy: 30,
+	}
+}
+
+func (e *PolicyError) Error() string {
+	return ErrWeakPassword.Error() + ": " + strings.Join(e.Violations, "; ")
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// Check returns a *PolicyError when password breaks the policy. personal are
+// values, like the username and email, the password must not contain.
+func (p Policy) Check(password string, personal ...string) (err error) {
+	var violations []string
+
+	length := utf8.RuneCountInString(password)
+
+	if p.MinLength > 0 && length < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d characters", p.MaxLength))
+	}
+
+	if classes := countClasses(password); p.MinClasses > 0 && classes < p.MinClasses {
+		violations = append(violations, fmt.Sprintf(
+			"must mix at least %d of lowercase, uppercase, digits and symbols",
+			p.MinClasses,
+		))
+	}
+
+	if containsPersonal(password, personal) {
+		violations = append(violations, "must not contain the username or email")
+	}
+
+	if p.MinEntropy > 0 && Entropy(password) < p.MinEntropy {
+		violations = append(violations, "is too easy to guess")
+	}
+
+	if p.Breached != nil {
+		count, err := p.Breached.Count(password)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			violations = append(violations, "appears in a data breach")
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// Entropy estimates the bits of password as its effective length times the
+// bits of the pools its characters come from. Characters that repeat the
+// previous one or continue a run like "abc" or "321" count a quarter.
+func Entropy(password string) (bits float64) {
+	var (
+		pool, effective float64
+		previous        rune
+		seen            [classOther + 1]bool
+		sizes           = [classOther + 1]float64{26, 26, 10, 33, 100}
+	)
+
+	for i, r := range []rune(password) {
+		if class := classOf(r); !seen[class] {
+			seen[class] = true
+			pool += sizes[class]
+		}
+
+		if delta := r - previous; i > 0 && delta >= -1 && delta <= 1 {
+			effective += 0.25
+		} else {
+			effective++
+		}
+
+		previous = r
+	}
+
+	if pool == 0 {
+		return 0
+	}
+
+	return effective * math.Log2(pool)
+}
+
+func classOf(r rune) int {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return classLower
+	case r >= 'A' && r <= 'Z':
+		return classUpper
+	case r >= '0' && r <= '9':
+		return classDigit
+	case r < utf8.RuneSelf:
+		return classSymbol
+	default:
+		return classOther
+	}
+}
+
+// countClasses counts the classes of password, characters outside ASCII count
+// as symbols.
+func countClasses(password string) (classes int) {
+	var seen [classOther]bool
+
+	for _, r := range password {
+		class := classOf(r)
+		if class == classOther {
+			class = classSymbol
+		}
+
+		seen[class] = true
+	}
+
+	for _, has := range seen {
+		if has {
+			classes++
+		}
+	}
+
+	return classes
+}
+
+func containsPersonal(password string, personal []string) (check bool) {
+	password = strings.ToLower(password)
+
+	for _, value := range personal {
+		value = strings.ToLower(value)
+		local, _, _ := strings.Cut(value, "@")
+
+		for _, part := range []string{value, local} {
+			if utf8.RuneCountInString(part) >= minPersonalLength && strings.Contains(password, part) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,160 @@
+package password_test
+
+import (
+	"crypto/sha1" //nolint:gosec,revive
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"app/internal/entity/mock"
+	"app/internal/password"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getBreachedListTest(t *testing.T, passwords ...string) *password.BreachedList {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for _, plain := range passwords {
+		sum := sha1.Sum([]byte(plain)) //nolint:gosec
+		encoded := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+		file, err := os.OpenFile(
+			filepath.Join(dir, encoded[:5]+".txt"),
+			os.O_APPEND|os.O_CREATE|os.O_WRONLY,
+			0o600,
+		)
+		assert.Nil(t, err)
+
+		_, err = file.WriteString("0000000000000000000000000000000000A:0\r\n" + encoded[5:] + ":42\r\n")
+		assert.Nil(t, err)
+		assert.Nil(t, file.Close())
+	}
+
+	list, err := password.NewBreachedList(dir)
+	assert.Nil(t, err)
+
+	return list
+}
+
+func TestPolicyCheck(t *testing.T) {
+	t.Parallel()
+
+	breached := getBreachedListTest(t, "correct-horse-42")
+
+	for _, tt := range []struct {
+		name          string
+		inPolicy      password.Policy
+		inPassword    string
+		outViolations []string
+	}{
+		{
+			name:       mock.NameNoError,
+			inPolicy:   password.DefaultPolicy(),
+			inPassword: "battery-staple-97",
+		},
+		{
+			name:       mock.NameNoError + "ZeroPolicy",
+			inPassword: "01234",
+		},
+		{
+			name:          "ErrorShortAndGuessable",
+			inPolicy:      password.DefaultPolicy(),
+			inPassword:    "01234",
+			outViolations: []string{"must be at least 8 characters", "is too easy to guess"},
+		},
+		{
+			name:          "ErrorLong",
+			inPolicy:      password.Policy{MaxLength: 4},
+			inPassword:    "battery",
+			outViolations: []string{"must be at most 4 characters"},
+		},
+		{
+			name:          "ErrorClasses",
+			inPolicy:      password.Policy{MinClasses: 3},
+			inPassword:    "batterystaple97",
+			outViolations: []string{"must mix at least 3 of lowercase, uppercase, digits and symbols"},
+		},
+		{
+			name:          "ErrorUsername",
+			inPolicy:      password.DefaultPolicy(),
+			inPassword:    "my-username-97",
+			outViolations: []string{"must not contain the username or email"},
+		},
+		{
+			name:          "ErrorEmailLocalPart",
+			inPolicy:      password.DefaultPolicy(),
+			inPassword:    "EMAIL-battery-97",
+			outViolations: []string{"must not contain the username or email"},
+		},
+		{
+			name:          "ErrorBreached",
+			inPolicy:      password.Policy{Breached: breached},
+			inPassword:    "correct-horse-42",
+			outViolations: []string{"appears in a data breach"},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.inPolicy.Check(tt.inPassword, mock.UsernameTest, mock.EmailTest)
+			if tt.outViolations == nil {
+				assert.Nil(t, err)
+
+				return
+			}
+
+			var policyErr *password.PolicyError
+
+			assert.ErrorIs(t, err, password.ErrWeakPassword)
+			assert.ErrorAs(t, err, &policyErr)
+			assert.Equal(t, tt.outViolations, policyErr.Violations)
+		})
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name    string
+		in      string
+		outLess string
+	}{
+		{name: "Repeat", in: "aaaaaaaa", outLess: "abzkqmwe"},
+		{name: "Sequence", in: "12345678", outLess: "18364502"},
+		{name: "Classes", in: "abcdwxyz", outLess: "aBcDwXyZ"},
+		{name: "Length", in: "xkq", outLess: "xkqpzmt"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Less(t, password.Entropy(tt.in), password.Entropy(tt.outLess))
+		})
+	}
+
+	assert.Zero(t, password.Entropy(""))
+}
+
+func TestBreachedList(t *testing.T) {
+	t.Parallel()
+
+	list := getBreachedListTest(t, "correct-horse-42")
+
+	count, err := list.Count("correct-horse-42")
+	assert.Nil(t, err)
+	assert.Equal(t, 42, count)
+
+	count, err = list.Count("battery-staple-97")
+	assert.Nil(t, err)
+	assert.Zero(t, count)
+
+	_, err = password.NewBreachedList(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
@@ -5,26 +5,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
-	"app/internal/entity"
+	"app/internal/backend"
 )
 
+const requestTimeout = time.Minute
+
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-type MyResponse interface {
-	entity.UserErrorResponse |
-		entity.IDErrorResponse |
-		entity.ErrorResponse |
-		entity.RowsErrorResponse |
-		entity.Token |
-		entity.IDUsernameEmailErrResponse |
-		entity.CheckErrResponse
-}
-
 type HTTPComponents struct {
 	url, method string
 }
@@ -33,72 +27,87 @@ func NewHTTPComponents(url, method string) HTTPComponents {
 	return HTTPComponents{url: url, method: method}
 }
 
-func RequestFunc[responseEntity MyResponse](
-	client HTTPClient,
-	body any,
+// backendClient sends the requests of the clients of the backend at url.
+type backendClient struct {
+	client HTTPClient
+	url    string
+}
+
+// request sends body as JSON, nothing when nil, and decodes the answer into
+// response.
+func (c backendClient) request(
+	ctx context.Context,
 	httpComponents HTTPComponents,
-	response *responseEntity,
+	body any,
+	response any,
 ) (err error) {
-	bodyJSON, err := json.Marshal(body)
-	if err != nil {
-		err = fmt.Errorf("error to make petition: %w", err)
+	_, err = c.requestStatus(ctx, httpComponents, body, response)
 
-		return err
-	}
+	return err
+}
 
-	ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Minute)
-	defer ctxCancel()
+// requestOptional is request for the operations backends may not serve. The
+// answers 404 and 405 tell they do not, which is reported as
+// backend.ErrNotSupported.
+func (c backendClient) requestOptional(
+	ctx context.Context,
+	httpComponents HTTPComponents,
+	body any,
+	response any,
+) (err error) {
+	status, err := c.requestStatus(ctx, httpComponents, body, response)
+	if status == http.StatusNotFound || status == http.StatusMethodNotAllowed {
+		return fmt.Errorf("%w: %s %s answered %d", backend.ErrNotSupported,
+			httpComponents.method, strings.TrimPrefix(httpComponents.url, c.url), status)
+	}
 
-	req, err := http.NewRequestWithContext(ctx, httpComponents.method, httpComponents.url, bytes.NewBuffer(bodyJSON))
-	if err != nil {
-		err = fmt.Errorf("error to make petition: %w", err)
+	return err
+}
 
-		return err
-	}
+// requestStatus is request, also returning the status code of the answer, or
+// 0 when there is none.
+func (c backendClient) requestStatus(
+	ctx context.Context,
+	httpComponents HTTPComponents,
+	body any,
+	response any,
+) (status int, err error) {
+	var reader io.Reader = http.NoBody
 
-	resp, err := client.Do(req)
-	if err != nil {
-		err = fmt.Errorf("error to make petition: %w", err)
+	if body != nil {
+		var bodyJSON []byte
 
-		return err
-	}
+		if bodyJSON, err = json.Marshal(body); err != nil {
+			err = fmt.Errorf("error to make petition: %w", err)
 
-	defer resp.Body.Close()
+			return 0, err
+		}
 
-	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
-		return fmt.Errorf("failed to decode request: %w", err)
+		reader = bytes.NewReader(bodyJSON)
 	}
 
-	return nil
-}
-
-func RequestFuncWithoutBody(
-	client HTTPClient,
-	httpComponents HTTPComponents,
-	response *entity.UsersErrorResponse,
-) (err error) {
-	ctx, ctxCancel := context.WithTimeout(context.TODO(), time.Minute)
+	ctx, ctxCancel := context.WithTimeout(ctx, requestTimeout)
 	defer ctxCancel()
 
-	req, err := http.NewRequestWithContext(ctx, httpComponents.method, httpComponents.url, bytes.NewBuffer(nil))
+	req, err := http.NewRequestWithContext(ctx, httpComponents.method, httpComponents.url, reader)
 	if err != nil {
 		err = fmt.Errorf("error to make petition: %w", err)
 
-		return err
+		return 0, err
 	}
 
-	resp, err := client.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		err = fmt.Errorf("error to make petition: %w", err)
 
-		return err
+		return 0, err
 	}
 
 	defer resp.Body.Close()
 
 	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
-		return fmt.Errorf("failed to decode request: %w", err)
+		return resp.StatusCode, fmt.Errorf("failed to decode request: %w", err)
 	}
 
-	return nil
+	return resp.StatusCode, nil
 }
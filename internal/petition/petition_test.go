@@ -1,12 +1,14 @@
 package petition_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
 
+	"app/internal/backend"
 	"app/internal/entity"
 	"app/internal/entity/mock"
 	"app/internal/petition"
@@ -17,18 +19,11 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestRequestFunc(t *testing.T) {
+func TestBackendClientErrors(t *testing.T) {
 	t.Parallel()
 
-	mockOK := serviceMock.NewMockClient(func(_ *http.Request) (*http.Response, error) {
-		response := &http.Response{
-			StatusCode: http.StatusOK,
-			Body: io.NopCloser(strings.NewReader(`{
-				"id": 1
-			}`)),
-		}
-
-		return response, nil
+	mockMalformed := serviceMock.NewMockClient(func(_ *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"id":`))}, nil
 	})
 
 	mockNotOK := serviceMock.NewMockClient(func(_ *http.Request) (*http.Response, error) {
@@ -36,181 +31,76 @@ func TestRequestFunc(t *testing.T) {
 	})
 
 	for _, tt := range []struct {
-		Response *entity.IDErrorResponse
-		client   petition.HTTPClient
-		body     any
-		name     string
-		inURL    string
-		inMethod string
-		outErr   string
+		client petition.HTTPClient
+		name   string
+		inURL  string
+		outErr string
 	}{
-		{
-			name:   "NoError",
-			client: mockOK,
-			body: entity.UsernameRequest{
-				Username: mock.UsernameTest,
-			},
-			inURL:    "localhost:8080",
-			inMethod: http.MethodPost,
-			Response: &entity.IDErrorResponse{},
-			outErr:   "",
-		},
-		{
-			name:     "ErrorMarshal",
-			client:   mockOK,
-			body:     func() {},
-			inURL:    "localhost:8080",
-			inMethod: http.MethodPost,
-			Response: &entity.IDErrorResponse{},
-			outErr:   "error to make petition",
-		},
 		{
 			name:   "ErrorURL",
-			client: mockOK,
-			body: entity.UsernameRequest{
-				Username: mock.UsernameTest,
-			},
-			inURL:    "%%",
-			inMethod: http.MethodPost,
-			Response: &entity.IDErrorResponse{},
-			outErr:   "error to make petition",
+			client: mockMalformed,
+			inURL:  "%%",
+			outErr: "error to make petition",
 		},
 		{
 			name:   "ErrorService",
 			client: mockNotOK,
-			body: entity.UsernameRequest{
-				Username: mock.UsernameTest,
-			},
-			inURL:    "localhost:8080",
-			inMethod: http.MethodPost,
-			Response: &entity.IDErrorResponse{},
-			outErr:   "error to make petition",
+			inURL:  "http://storage:8080",
+			outErr: "error to make petition",
 		},
 		{
 			name:   "ErrorDecode",
-			client: mockOK,
-			body: entity.UsernameRequest{
-				Username: mock.UsernameTest,
-			},
-			inURL:    "localhost:8080",
-			inMethod: http.MethodPost,
-			Response: nil,
-			outErr:   "failed to decode request",
+			client: mockMalformed,
+			inURL:  "http://storage:8080",
+			outErr: "failed to decode request",
 		},
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := petition.RequestFunc(
-				tt.client,
-				tt.body,
-				petition.NewHTTPComponents(
-					tt.inURL,
-					tt.inMethod,
-				),
-				tt.Response,
-			)
-
-			if tt.name == "NoError" {
-				assert.Nil(t, err)
-				assert.Equal(t, mock.IDTest, tt.Response.ID)
-				assert.Zero(t, tt.Response.Err)
-			} else {
-				assert.NotNil(t, err)
-				assert.ErrorContains(t, err, tt.outErr)
-			}
+			storage := petition.NewStorageClient(tt.client, tt.inURL)
+
+			// With a body and without one.
+			_, err := storage.GetIDByUsername(context.Background(), mock.UsernameTest)
+			assert.ErrorContains(t, err, tt.outErr)
+
+			_, _, err = storage.ListUsers(context.Background(), entity.ListUsersOptions{})
+			assert.ErrorContains(t, err, tt.outErr)
 		})
 	}
 }
 
-func TestRequestFuncWithoutBody(t *testing.T) {
+func TestBackendClientNotSupported(t *testing.T) {
 	t.Parallel()
 
-	mockOK := serviceMock.NewMockClient(func(_ *http.Request) (*http.Response, error) {
-		response := &http.Response{
-			StatusCode: http.StatusOK,
-			Body: io.NopCloser(strings.NewReader(`{
-				"users": [
-					{
-						"id":1,
-						"username":"username",
-						"password":"password",
-						"email":"email@email.com"
-					}
-				]
-			}`)),
-		}
-
-		return response, nil
-	})
-
-	mockNotOK := serviceMock.NewMockClient(func(_ *http.Request) (*http.Response, error) {
-		return nil, fmt.Errorf("%w: error", service.ErrWebServer)
-	})
-
 	for _, tt := range []struct {
-		Response *entity.UsersErrorResponse
-		client   petition.HTTPClient
 		name     string
-		inURL    string
-		inMethod string
-		outErr   string
+		inBody   string
+		inStatus int
 	}{
-		{
-			name:     "NoError",
-			client:   mockOK,
-			inURL:    "localhost:8080",
-			inMethod: http.MethodPost,
-			Response: &entity.UsersErrorResponse{},
-			outErr:   "",
-		},
-		{
-			name:     "ErrorURL",
-			client:   mockOK,
-			inURL:    "%%",
-			inMethod: http.MethodPost,
-			Response: &entity.UsersErrorResponse{},
-			outErr:   "error to make petition",
-		},
-		{
-			name:     "ErrorService",
-			client:   mockNotOK,
-			inURL:    "localhost:8080",
-			inMethod: http.MethodPost,
-			Response: &entity.UsersErrorResponse{},
-			outErr:   "error to make petition",
-		},
-		{
-			name:     "ErrorDecode",
-			client:   mockOK,
-			inURL:    "localhost:8080",
-			inMethod: http.MethodPost,
-			Response: nil,
-			outErr:   "failed to decode request",
-		},
+		{name: "NotFound", inBody: "404 page not found\n", inStatus: http.StatusNotFound},
+		{name: "MethodNotAllowed", inBody: "", inStatus: http.StatusMethodNotAllowed},
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := petition.RequestFuncWithoutBody(
-				tt.client,
-				petition.NewHTTPComponents(
-					tt.inURL,
-					tt.inMethod,
-				),
-				tt.Response,
-			)
-
-			if tt.name == "NoError" {
-				assert.Nil(t, err)
-				assert.NotNil(t, tt.Response.Users)
-				assert.Zero(t, tt.Response.Err)
-			} else {
-				assert.NotNil(t, err)
-				assert.ErrorContains(t, err, tt.outErr)
-			}
+			ctx := context.Background()
+			client := serviceMock.NewMockClient(func(_ *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: tt.inStatus, Body: io.NopCloser(strings.NewReader(tt.inBody))}, nil
+			})
+
+			err := petition.NewTokenClient(client, "http://token:8080").RevokeUser(ctx, mock.IDTest)
+			assert.ErrorIs(t, err, backend.ErrNotSupported)
+
+			storage := petition.NewStorageClient(client, "http://storage:8080")
+
+			err = storage.SetDisabled(ctx, mock.IDTest, true)
+			assert.ErrorIs(t, err, backend.ErrNotSupported)
+
+			err = storage.SetRole(ctx, mock.IDTest, entity.RoleAdmin)
+			assert.ErrorIs(t, err, backend.ErrNotSupported)
 		})
 	}
 }
@@ -0,0 +1,48 @@
+package petition
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"app/internal/signature"
+)
+
+// signingClient signs the requests of client, see signature.Signer.
+type signingClient struct {
+	client HTTPClient
+	signer *signature.Signer
+}
+
+// NewSigningClient returns a client that signs every request before client
+// sends it.
+func NewSigningClient(client HTTPClient, signer *signature.Signer) HTTPClient {
+	return &signingClient{client: client, signer: signer}
+}
+
+func (c *signingClient) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("failed to read body: %w", err)
+		}
+
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if err := c.signer.Sign(req, body); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send signed request: %w", err)
+	}
+
+	return resp, nil
+}
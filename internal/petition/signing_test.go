@@ -0,0 +1,64 @@
+package petition_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal/entity/mock"
+	"app/internal/petition"
+	"app/internal/signature"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSigningClient(t *testing.T) {
+	t.Parallel()
+
+	verifier, err := signature.NewVerifier([]byte(mock.SecretTest), 0, nil)
+	assert.Nil(t, err)
+
+	backend := httptest.NewServer(verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1}`))
+	})))
+	t.Cleanup(backend.Close)
+
+	for _, tt := range []struct {
+		name  string
+		inKey string
+		outID int
+		// outErr is the err field the verifier answers with.
+		outErr string
+	}{
+		{
+			name:  mock.NameNoError,
+			inKey: mock.SecretTest,
+			outID: 1,
+		},
+		{
+			name:   "ErrorKey",
+			inKey:  "other",
+			outErr: "invalid signature",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			signer, err := signature.NewSigner([]byte(tt.inKey), nil)
+			assert.Nil(t, err)
+
+			storage := petition.NewStorageClient(petition.NewSigningClient(&http.Client{}, signer), backend.URL)
+
+			id, err := storage.GetIDByUsername(context.Background(), mock.UsernameTest)
+			if tt.outErr != "" {
+				assert.EqualError(t, err, tt.outErr)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			assert.Equal(t, tt.outID, id)
+		})
+	}
+}
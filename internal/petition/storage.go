@@ -0,0 +1,209 @@
+package petition
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"app/internal/backend"
+	"app/internal/entity"
+)
+
+// StorageClient is the backend.StorageClient of the storage service at url.
+type StorageClient struct {
+	backendClient
+}
+
+var _ backend.StorageClient = (*StorageClient)(nil)
+
+// NewStorageClient returns the client of the storage service at url, such as
+// "http://storage:8080".
+func NewStorageClient(client HTTPClient, url string) *StorageClient {
+	return &StorageClient{backendClient{client: client, url: strings.TrimSuffix(url, "/")}}
+}
+
+// CreateUser sends POST /user.
+func (c *StorageClient) CreateUser(ctx context.Context, user entity.UsernamePasswordEmailRequest) (err error) {
+	var response entity.ErrorResponse
+
+	if err = c.request(ctx, NewHTTPComponents(c.url+"/user", http.MethodPost), user, &response); err != nil {
+		return err
+	}
+
+	return responseError(response.Err)
+}
+
+// GetIDByUsername sends GET /id/username.
+func (c *StorageClient) GetIDByUsername(ctx context.Context, username string) (id int, err error) {
+	var response entity.IDErrorResponse
+
+	if err = c.request(
+		ctx,
+		NewHTTPComponents(c.url+"/id/username", http.MethodGet),
+		entity.UsernameRequest{Username: username},
+		&response,
+	); err != nil {
+		return 0, err
+	}
+
+	return response.ID, responseError(response.Err)
+}
+
+// GetUserByCredentials sends GET /user/username_password.
+func (c *StorageClient) GetUserByCredentials(
+	ctx context.Context,
+	username, password string,
+) (user entity.User, err error) {
+	var response entity.UserErrorResponse
+
+	if err = c.request(
+		ctx,
+		NewHTTPComponents(c.url+"/user/username_password", http.MethodGet),
+		entity.UsernamePasswordRequest{Username: username, Password: password},
+		&response,
+	); err != nil {
+		return entity.User{}, err
+	}
+
+	return response.User, responseError(response.Err)
+}
+
+// GetUserByID sends GET /user/id.
+func (c *StorageClient) GetUserByID(ctx context.Context, id int) (user entity.User, err error) {
+	var response entity.UserErrorResponse
+
+	if err = c.request(
+		ctx,
+		NewHTTPComponents(c.url+"/user/id", http.MethodGet),
+		entity.IDRequest{ID: id},
+		&response,
+	); err != nil {
+		return entity.User{}, err
+	}
+
+	return response.User, responseError(response.Err)
+}
+
+// UpdatePassword sends PUT /user/password.
+func (c *StorageClient) UpdatePassword(ctx context.Context, id int, password string) (err error) {
+	var response entity.ErrorResponse
+
+	if err = c.request(
+		ctx,
+		NewHTTPComponents(c.url+"/user/password", http.MethodPut),
+		entity.IDPasswordRequest{ID: id, Password: password},
+		&response,
+	); err != nil {
+		return err
+	}
+
+	return responseError(response.Err)
+}
+
+// SetDisabled sends PUT /user/disabled, see requestOptional.
+func (c *StorageClient) SetDisabled(ctx context.Context, id int, disabled bool) (err error) {
+	var response entity.ErrorResponse
+
+	if err = c.requestOptional(
+		ctx,
+		NewHTTPComponents(c.url+"/user/disabled", http.MethodPut),
+		entity.IDDisabledRequest{ID: id, Disabled: disabled},
+		&response,
+	); err != nil {
+		return err
+	}
+
+	return responseError(response.Err)
+}
+
+// SetRole sends PUT /user/role, see requestOptional.
+func (c *StorageClient) SetRole(ctx context.Context, id int, role string) (err error) {
+	var response entity.ErrorResponse
+
+	if err = c.requestOptional(
+		ctx,
+		NewHTTPComponents(c.url+"/user/role", http.MethodPut),
+		entity.IDRoleRequest{ID: id, Role: role},
+		&response,
+	); err != nil {
+		return err
+	}
+
+	return responseError(response.Err)
+}
+
+// DeleteUser sends DELETE /user.
+func (c *StorageClient) DeleteUser(ctx context.Context, id int) (err error) {
+	var response entity.ErrorResponse
+
+	if err = c.request(
+		ctx,
+		NewHTTPComponents(c.url+"/user", http.MethodDelete),
+		entity.IDRequest{ID: id},
+		&response,
+	); err != nil {
+		return err
+	}
+
+	return responseError(response.Err)
+}
+
+// ListUsers sends GET /users with the options in the query.
+func (c *StorageClient) ListUsers(
+	ctx context.Context,
+	options entity.ListUsersOptions,
+) (page entity.UsersPage, paged bool, err error) {
+	var response entity.UsersErrorResponse
+
+	target := c.url + "/users"
+	if options != (entity.ListUsersOptions{}) {
+		target += "?" + listOptionsQuery(options).Encode()
+	}
+
+	if err = c.request(ctx, NewHTTPComponents(target, http.MethodGet), nil, &response); err != nil {
+		return entity.UsersPage{}, false, err
+	}
+
+	if err = responseError(response.Err); err != nil {
+		return entity.UsersPage{}, false, err
+	}
+
+	return entity.UsersPage{
+		Users:      response.Users,
+		NextCursor: response.NextCursor,
+		Total:      response.Total,
+	}, response.Paged, nil
+}
+
+func listOptionsQuery(options entity.ListUsersOptions) url.Values {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(options.Limit))
+	query.Set("sort", options.Sort)
+
+	if options.Cursor != "" {
+		query.Set("cursor", options.Cursor)
+	} else {
+		query.Set("offset", strconv.Itoa(options.Offset))
+	}
+
+	if options.UsernamePrefix != "" {
+		query.Set("usernamePrefix", options.UsernamePrefix)
+	}
+
+	if options.EmailDomain != "" {
+		query.Set("emailDomain", options.EmailDomain)
+	}
+
+	return query
+}
+
+// responseError is the error a backend answered with, nil for "".
+func responseError(message string) error {
+	if message == "" {
+		return nil
+	}
+
+	return &backend.ResponseError{Message: message}
+}
@@ -0,0 +1,171 @@
+package petition_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"app/internal/backend"
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/petition"
+
+	serviceMock "app/internal/service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// requestTest is what a backend client sent.
+type requestTest struct {
+	method, target, body string
+}
+
+// newBackendTest returns a client answering answer and recording the request
+// it was sent in sent.
+func newBackendTest(answer string, sent *requestTest) petition.HTTPClient {
+	return serviceMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		*sent = requestTest{method: req.Method, target: req.URL.String(), body: string(body)}
+
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(answer))}, nil
+	})
+}
+
+func TestStorageClient(t *testing.T) {
+	t.Parallel()
+
+	user := entity.User{ID: mock.IDTest, Username: mock.UsernameTest, Email: mock.EmailTest}
+
+	for _, tt := range []struct {
+		call    func(*petition.StorageClient) (any, error)
+		name    string
+		inBody  string
+		out     any
+		outSent requestTest
+	}{
+		{
+			name: "CreateUser",
+			call: func(c *petition.StorageClient) (any, error) {
+				return nil, c.CreateUser(context.Background(), entity.UsernamePasswordEmailRequest{
+					Username: mock.UsernameTest,
+					Password: mock.PasswordTest,
+					Email:    mock.EmailTest,
+				})
+			},
+			inBody: `{}`,
+			outSent: requestTest{
+				http.MethodPost,
+				"http://storage:8080/user",
+				`{"username":"username","password":"password","email":"email@email.com"}`,
+			},
+		},
+		{
+			name: "GetIDByUsername",
+			call: func(c *petition.StorageClient) (any, error) {
+				return c.GetIDByUsername(context.Background(), mock.UsernameTest)
+			},
+			inBody:  `{"id":1}`,
+			out:     mock.IDTest,
+			outSent: requestTest{http.MethodGet, "http://storage:8080/id/username", `{"username":"username"}`},
+		},
+		{
+			name: "GetUserByCredentials",
+			call: func(c *petition.StorageClient) (any, error) {
+				return c.GetUserByCredentials(context.Background(), mock.UsernameTest, mock.PasswordTest)
+			},
+			inBody: `{"user":{"id":1,"username":"username","email":"email@email.com"}}`,
+			out:    user,
+			outSent: requestTest{
+				http.MethodGet,
+				"http://storage:8080/user/username_password",
+				`{"username":"username","password":"password"}`,
+			},
+		},
+		{
+			name: "GetUserByID",
+			call: func(c *petition.StorageClient) (any, error) {
+				return c.GetUserByID(context.Background(), mock.IDTest)
+			},
+			inBody:  `{"user":{"id":1,"username":"username","email":"email@email.com"}}`,
+			out:     user,
+			outSent: requestTest{http.MethodGet, "http://storage:8080/user/id", `{"id":1}`},
+		},
+		{
+			name: "UpdatePassword",
+			call: func(c *petition.StorageClient) (any, error) {
+				return nil, c.UpdatePassword(context.Background(), mock.IDTest, mock.PasswordTest)
+			},
+			inBody:  `{}`,
+			outSent: requestTest{http.MethodPut, "http://storage:8080/user/password", `{"password":"password","id":1}`},
+		},
+		{
+			name: "SetDisabled",
+			call: func(c *petition.StorageClient) (any, error) {
+				return nil, c.SetDisabled(context.Background(), mock.IDTest, true)
+			},
+			inBody:  `{}`,
+			outSent: requestTest{http.MethodPut, "http://storage:8080/user/disabled", `{"id":1,"disabled":true}`},
+		},
+		{
+			name: "SetRole",
+			call: func(c *petition.StorageClient) (any, error) {
+				return nil, c.SetRole(context.Background(), mock.IDTest, entity.RoleAdmin)
+			},
+			inBody:  `{}`,
+			outSent: requestTest{http.MethodPut, "http://storage:8080/user/role", `{"role":"admin","id":1}`},
+		},
+		{
+			name: "DeleteUser",
+			call: func(c *petition.StorageClient) (any, error) {
+				return nil, c.DeleteUser(context.Background(), mock.IDTest)
+			},
+			inBody:  `{}`,
+			outSent: requestTest{http.MethodDelete, "http://storage:8080/user", `{"id":1}`},
+		},
+		{
+			name: "ListUsers",
+			call: func(c *petition.StorageClient) (any, error) {
+				page, paged, err := c.ListUsers(context.Background(), entity.ListUsersOptions{Limit: 1, Sort: "id"})
+
+				return fmt.Sprint(page.Users, page.NextCursor, paged), err
+			},
+			inBody:  `{"users":[{"id":1}],"nextCursor":"next","paged":true}`,
+			out:     fmt.Sprint([]entity.User{{ID: 1}}, "next", true),
+			outSent: requestTest{http.MethodGet, "http://storage:8080/users?limit=1&offset=0&sort=id", ""},
+		},
+		{
+			name: "ListAllUsers",
+			call: func(c *petition.StorageClient) (any, error) {
+				page, paged, err := c.ListUsers(context.Background(), entity.ListUsersOptions{})
+
+				return fmt.Sprint(page.Users, paged), err
+			},
+			inBody:  `{"users":[{"id":1}]}`,
+			out:     fmt.Sprint([]entity.User{{ID: 1}}, false),
+			outSent: requestTest{http.MethodGet, "http://storage:8080/users", ""},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sent requestTest
+
+			out, err := tt.call(petition.NewStorageClient(newBackendTest(tt.inBody, &sent), "http://storage:8080/"))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.outSent, sent)
+
+			if tt.out != nil {
+				assert.Equal(t, tt.out, out)
+			}
+
+			// The same call fails with the error the backend answers.
+			_, err = tt.call(petition.NewStorageClient(newBackendTest(`{"err":"backend"}`, &sent), "http://storage:8080"))
+			assert.ErrorIs(t, err, backend.ErrResponse)
+			assert.EqualError(t, err, "backend")
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package petition
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultReloadInterval = 10 * time.Second
+
+var errNoCertificates = errors.New("no certificates found")
+
+// TLSConfig configures HTTPS, and mutual TLS, to the backends.
+type TLSConfig struct {
+	// CAFile is a PEM bundle of the CAs trusted for the backends, "" trusts
+	// the system ones.
+	CAFile string
+	// CertFile and KeyFile are the client certificate of the gateway.
+	CertFile string
+	KeyFile  string
+	// ServerNames overrides, by host:port of the backend URL, the name sent
+	// in the SNI and verified in the certificate of the backend.
+	ServerNames map[string]string
+	// ReloadInterval is how often the files are checked for changes, 0
+	// means every 10 seconds.
+	ReloadInterval time.Duration
+}
+
+// NewClient returns the client of the backends. With files in cfg, its TLS
+// settings are loaded again when they change.
+func NewClient(cfg TLSConfig) (client *http.Client, err error) {
+	if cfg.CAFile == "" && cfg.CertFile == "" && cfg.KeyFile == "" && len(cfg.ServerNames) == 0 {
+		return &http.Client{}, nil
+	}
+
+	transport := &reloadingTransport{cfg: cfg}
+	if transport.current, err = newTransport(cfg); err != nil {
+		return nil, err
+	}
+
+	transport.mods, _ = transport.modTimes()
+	transport.checked = time.Now()
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// reloadingTransport builds a new http.Transport when the files of cfg
+// change, and keeps the current one while they do not make a valid one.
+type reloadingTransport struct {
+	current *http.Transport
+	checked time.Time
+	mods    []time.Time
+	cfg     TLSConfig
+	mutex   sync.Mutex
+}
+
+func (t *reloadingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport().RoundTrip(req)
+}
+
+func (t *reloadingTransport) transport() *http.Transport {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	interval := t.cfg.ReloadInterval
+	if interval == 0 {
+		interval = defaultReloadInterval
+	}
+
+	if time.Since(t.checked) < interval {
+		return t.current
+	}
+
+	t.checked = time.Now()
+
+	mods, err := t.modTimes()
+	if err != nil || equalTimes(mods, t.mods) {
+		return t.current
+	}
+
+	next, err := newTransport(t.cfg)
+	if err != nil {
+		log.Println("keeping the previous backend TLS settings:", err)
+
+		return t.current
+	}
+
+	t.current.CloseIdleConnections()
+	t.current, t.mods = next, mods
+
+	return t.current
+}
+
+func (t *reloadingTransport) modTimes() (mods []time.Time, err error) {
+	for _, file := range []string{t.cfg.CAFile, t.cfg.CertFile, t.cfg.KeyFile} {
+		if file == "" {
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+
+		mods = append(mods, info.ModTime())
+	}
+
+	return mods, nil
+}
+
+// newTransport loads the files of cfg into a clone of the default
+// transport.
+func newTransport(cfg TLSConfig) (transport *http.Transport, err error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", errNoCertificates)
+		}
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport, _ = http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	if len(cfg.ServerNames) != 0 {
+		transport.DialTLSContext = dialTLS(tlsConfig, cfg.ServerNames)
+	}
+
+	return transport, nil
+}
+
+// dialTLS connects like http.Transport does, with the server name of the
+// address in names when there is one.
+func dialTLS(
+	config *tls.Config,
+	names map[string]string,
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{}
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial %s: %w", addr, err)
+		}
+
+		config := config.Clone()
+		if config.ServerName = names[addr]; config.ServerName == "" {
+			config.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+
+		tlsConn := tls.Client(conn, config)
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+
+			return nil, fmt.Errorf("failed TLS handshake with %s: %w", addr, err)
+		}
+
+		return tlsConn, nil
+	}
+}
+
+func equalTimes(a, b []time.Time) (check bool) {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
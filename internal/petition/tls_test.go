@@ -0,0 +1,245 @@
+package petition_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"app/internal/petition"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// certTest is a certificate with its key, signed by parent or self-signed.
+type certTest struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func TestNewClientMutualTLS(t *testing.T) {
+	t.Parallel()
+
+	ca := newCertTest(t, "ca", nil, nil)
+	otherCA := newCertTest(t, "other", nil, nil)
+	serverCert := newCertTest(t, "storage.internal", []string{"storage.internal"}, ca)
+	clientCert := newCertTest(t, "gateway", nil, ca)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	backend.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{serverCert.tlsCertificate()},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	backend.Config.ErrorLog = log.New(io.Discard, "", 0)
+	backend.StartTLS()
+	t.Cleanup(backend.Close)
+
+	_, port, err := net.SplitHostPort(backend.Listener.Addr().String())
+	assert.Nil(t, err)
+
+	url := "https://127.0.0.1:" + port
+	names := map[string]string{"127.0.0.1:" + port: "storage.internal"}
+
+	for _, tt := range []struct {
+		name          string
+		inClient      *certTest
+		inCA          *certTest
+		inServerNames map[string]string
+		outErr        string
+	}{
+		{
+			name:          "NoError",
+			inClient:      clientCert,
+			inCA:          ca,
+			inServerNames: names,
+		},
+		{
+			name:     "ErrorServerName",
+			inClient: clientCert,
+			inCA:     ca,
+			outErr:   "cannot validate certificate for 127.0.0.1",
+		},
+		{
+			name:          "ErrorUnknownCA",
+			inClient:      clientCert,
+			inCA:          otherCA,
+			inServerNames: names,
+			outErr:        "certificate signed by unknown authority",
+		},
+		{
+			name:          "ErrorWithoutClientCertificate",
+			inCA:          ca,
+			inServerNames: names,
+			outErr:        "error to make petition",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			cfg := petition.TLSConfig{
+				CAFile:      filepath.Join(dir, "ca.pem"),
+				ServerNames: tt.inServerNames,
+			}
+
+			tt.inCA.writeCert(t, cfg.CAFile)
+
+			if tt.inClient != nil {
+				cfg.CertFile, cfg.KeyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+				tt.inClient.writeCert(t, cfg.CertFile)
+				tt.inClient.writeKey(t, cfg.KeyFile)
+			}
+
+			client, err := petition.NewClient(cfg)
+			assert.Nil(t, err)
+
+			id, err := petition.NewStorageClient(client, url).GetIDByUsername(context.Background(), "username")
+			if tt.outErr != "" {
+				assert.ErrorContains(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, 1, id)
+		})
+	}
+}
+
+func TestNewClientReload(t *testing.T) {
+	t.Parallel()
+
+	first := newCertTest(t, "first", nil, nil)
+	second := newCertTest(t, "second", nil, nil)
+
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	backend.TLS = &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{second.tlsCertificate()},
+	}
+	backend.Config.ErrorLog = log.New(io.Discard, "", 0)
+	backend.StartTLS()
+	t.Cleanup(backend.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	first.writeCert(t, caFile)
+
+	client, err := petition.NewClient(petition.TLSConfig{CAFile: caFile, ReloadInterval: time.Nanosecond})
+	assert.Nil(t, err)
+
+	storage := petition.NewStorageClient(client, backend.URL)
+
+	_, err = storage.GetIDByUsername(context.Background(), "username")
+	assert.ErrorContains(t, err, "unknown authority")
+
+	// An invalid bundle keeps the previous one.
+	assert.Nil(t, os.WriteFile(caFile, []byte("partial"), 0o600))
+	assert.Nil(t, os.Chtimes(caFile, time.Now().Add(time.Minute), time.Now().Add(time.Minute)))
+	_, err = storage.GetIDByUsername(context.Background(), "username")
+	assert.ErrorContains(t, err, "unknown authority")
+
+	second.writeCert(t, caFile)
+	assert.Nil(t, os.Chtimes(caFile, time.Now().Add(time.Hour), time.Now().Add(time.Hour)))
+	_, err = storage.GetIDByUsername(context.Background(), "username")
+	assert.Nil(t, err)
+}
+
+func TestNewClientErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.pem")
+	assert.Nil(t, os.WriteFile(invalid, []byte("not a certificate"), 0o600))
+
+	_, err := petition.NewClient(petition.TLSConfig{CAFile: filepath.Join(dir, "missing.pem")})
+	assert.ErrorContains(t, err, "failed to read CA bundle")
+
+	_, err = petition.NewClient(petition.TLSConfig{CAFile: invalid})
+	assert.ErrorContains(t, err, "no certificates found")
+
+	_, err = petition.NewClient(petition.TLSConfig{CertFile: invalid, KeyFile: invalid})
+	assert.ErrorContains(t, err, "failed to load client certificate")
+
+	client, err := petition.NewClient(petition.TLSConfig{})
+	assert.Nil(t, err)
+	assert.Nil(t, client.Transport)
+}
+
+func newCertTest(t *testing.T, name string, dnsNames []string, parent *certTest) *certTest {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	assert.Nil(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+
+	return &certTest{cert: cert, key: key, der: der}
+}
+
+func (c *certTest) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func (c *certTest) writeCert(t *testing.T, file string) {
+	t.Helper()
+
+	assert.Nil(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600))
+}
+
+func (c *certTest) writeKey(t *testing.T, file string) {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600))
+}
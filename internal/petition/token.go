@@ -0,0 +1,127 @@
+package petition
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"app/internal/backend"
+	"app/internal/entity"
+)
+
+// TokenClient is the backend.TokenClient of the token service at url.
+type TokenClient struct {
+	backendClient
+}
+
+var _ backend.TokenClient = (*TokenClient)(nil)
+
+// NewTokenClient returns the client of the token service at url, such as
+// "http://token:8080".
+func NewTokenClient(client HTTPClient, url string) *TokenClient {
+	return &TokenClient{backendClient{client: client, url: strings.TrimSuffix(url, "/")}}
+}
+
+// Generate sends POST /generate.
+func (c *TokenClient) Generate(ctx context.Context, claims entity.Claims, secret string) (token string, err error) {
+	var response entity.Token
+
+	if err = c.request(
+		ctx,
+		NewHTTPComponents(c.url+"/generate", http.MethodPost),
+		entity.IDUsernameEmailSecretRequest{
+			ID:       claims.ID,
+			Username: claims.Username,
+			Email:    claims.Email,
+			Secret:   secret,
+			Role:     claims.Role,
+		},
+		&response,
+	); err != nil {
+		return "", err
+	}
+
+	return response.Token, nil
+}
+
+// Store sends POST /token.
+func (c *TokenClient) Store(ctx context.Context, token string) (err error) {
+	return c.tokenRequest(ctx, token, http.MethodPost)
+}
+
+// Check sends POST /check.
+func (c *TokenClient) Check(ctx context.Context, token string) (check bool, err error) {
+	var response entity.CheckErrResponse
+
+	if err = c.request(
+		ctx,
+		NewHTTPComponents(c.url+"/check", http.MethodPost),
+		entity.Token{Token: token},
+		&response,
+	); err != nil {
+		return false, err
+	}
+
+	return response.Check, responseError(response.Err)
+}
+
+// Extract sends POST /extract.
+func (c *TokenClient) Extract(ctx context.Context, token, secret string) (claims entity.Claims, err error) {
+	var response entity.IDUsernameEmailErrResponse
+
+	if err = c.request(
+		ctx,
+		NewHTTPComponents(c.url+"/extract", http.MethodPost),
+		entity.TokenSecretRequest{Token: token, Secret: secret},
+		&response,
+	); err != nil {
+		return entity.Claims{}, err
+	}
+
+	if err = responseError(response.Err); err != nil {
+		return entity.Claims{}, err
+	}
+
+	return entity.Claims{
+		ID:       response.ID,
+		Username: response.Username,
+		Email:    response.Email,
+		Role:     response.Role,
+	}, nil
+}
+
+// Revoke sends DELETE /token.
+func (c *TokenClient) Revoke(ctx context.Context, token string) (err error) {
+	return c.tokenRequest(ctx, token, http.MethodDelete)
+}
+
+// RevokeUser sends DELETE /tokens, see requestOptional.
+func (c *TokenClient) RevokeUser(ctx context.Context, id int) (err error) {
+	var response entity.ErrorResponse
+
+	if err = c.requestOptional(
+		ctx,
+		NewHTTPComponents(c.url+"/tokens", http.MethodDelete),
+		entity.IDRequest{ID: id},
+		&response,
+	); err != nil {
+		return err
+	}
+
+	return responseError(response.Err)
+}
+
+func (c *TokenClient) tokenRequest(ctx context.Context, token, method string) (err error) {
+	var response entity.ErrorResponse
+
+	if err = c.request(
+		ctx,
+		NewHTTPComponents(c.url+"/token", method),
+		entity.Token{Token: token},
+		&response,
+	); err != nil {
+		return err
+	}
+
+	return responseError(response.Err)
+}
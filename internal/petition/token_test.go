@@ -0,0 +1,111 @@
+package petition_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"app/internal/backend"
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/petition"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenClient(t *testing.T) {
+	t.Parallel()
+
+	claims := entity.Claims{ID: mock.IDTest, Username: mock.UsernameTest, Email: mock.EmailTest, Role: entity.RoleUser}
+
+	for _, tt := range []struct {
+		call    func(*petition.TokenClient) (any, error)
+		name    string
+		inBody  string
+		out     any
+		outSent requestTest
+		// outErr is whether an err in the answer fails the call.
+		outErr bool
+	}{
+		{
+			name: "Generate",
+			call: func(c *petition.TokenClient) (any, error) {
+				return c.Generate(context.Background(), claims, mock.SecretTest)
+			},
+			inBody: `{"token":"token"}`,
+			out:    mock.TokenTest,
+			outSent: requestTest{
+				http.MethodPost,
+				"http://token:8080/generate",
+				`{"username":"username","email":"email@email.com","secret":"secret","role":"user","id":1}`,
+			},
+		},
+		{
+			name: "Store",
+			call: func(c *petition.TokenClient) (any, error) {
+				return nil, c.Store(context.Background(), mock.TokenTest)
+			},
+			inBody:  `{}`,
+			outSent: requestTest{http.MethodPost, "http://token:8080/token", `{"token":"token"}`},
+			outErr:  true,
+		},
+		{
+			name: "Check",
+			call: func(c *petition.TokenClient) (any, error) {
+				return c.Check(context.Background(), mock.TokenTest)
+			},
+			inBody:  `{"check":true}`,
+			out:     true,
+			outSent: requestTest{http.MethodPost, "http://token:8080/check", `{"token":"token"}`},
+			outErr:  true,
+		},
+		{
+			name: "Extract",
+			call: func(c *petition.TokenClient) (any, error) {
+				return c.Extract(context.Background(), mock.TokenTest, mock.SecretTest)
+			},
+			inBody:  `{"id":1,"username":"username","email":"email@email.com","role":"user"}`,
+			out:     claims,
+			outSent: requestTest{http.MethodPost, "http://token:8080/extract", `{"token":"token","secret":"secret"}`},
+			outErr:  true,
+		},
+		{
+			name: "Revoke",
+			call: func(c *petition.TokenClient) (any, error) {
+				return nil, c.Revoke(context.Background(), mock.TokenTest)
+			},
+			inBody:  `{}`,
+			outSent: requestTest{http.MethodDelete, "http://token:8080/token", `{"token":"token"}`},
+			outErr:  true,
+		},
+		{
+			name: "RevokeUser",
+			call: func(c *petition.TokenClient) (any, error) {
+				return nil, c.RevokeUser(context.Background(), mock.IDTest)
+			},
+			inBody:  `{}`,
+			outSent: requestTest{http.MethodDelete, "http://token:8080/tokens", `{"id":1}`},
+			outErr:  true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sent requestTest
+
+			out, err := tt.call(petition.NewTokenClient(newBackendTest(tt.inBody, &sent), "http://token:8080"))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.outSent, sent)
+
+			if tt.out != nil {
+				assert.Equal(t, tt.out, out)
+			}
+
+			if tt.outErr {
+				_, err = tt.call(petition.NewTokenClient(newBackendTest(`{"err":"backend"}`, &sent), "http://token:8080"))
+				assert.ErrorIs(t, err, backend.ErrResponse)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+// Package revocation caches the answers of the token service about whether
+// tokens are revoked, so authenticated requests do not all wait on it.
+package revocation
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"app/internal/backend"
+)
+
+// Defaults of Config.
+const (
+	DefaultTTL         = 5 * time.Second
+	DefaultNegativeTTL = time.Minute
+	DefaultMaxEntries  = 10000
+)
+
+// Config bounds how stale and how large a Cache gets.
+type Config struct {
+	// TTL is how long a valid token is trusted without asking again.
+	TTL time.Duration
+	// NegativeTTL is how long a revoked token is remembered. Revoked tokens
+	// stay revoked, it only bounds the memory they take.
+	NegativeTTL time.Duration
+	// MaxEntries evicts the least recently used tokens beyond it.
+	MaxEntries int
+}
+
+// Stats are the counters of a Cache.
+type Stats struct {
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	Evictions uint64  `json:"evictions"`
+	HitRate   float64 `json:"hitRate"`
+	Entries   int     `json:"entries"`
+}
+
+// Cache is a backend.TokenClient answering Check from memory while the
+// answer of next is fresh. Tokens are kept by their SHA-256.
+//
+// The answers of next are asked outside the lock. generation is bumped by
+// every revocation and invalidation, an answer asked before is dropped
+// rather than kept over what the revocation left.
+type Cache struct {
+	backend.TokenClient
+	now                     func() time.Time
+	entries                 map[[sha256.Size]byte]*list.Element
+	lru                     *list.List
+	cfg                     Config
+	generation              uint64
+	hits, misses, evictions atomic.Uint64
+	mutex                   sync.Mutex
+}
+
+var _ backend.TokenClient = (*Cache)(nil)
+
+type entry struct {
+	expires time.Time
+	key     [sha256.Size]byte
+	check   bool
+}
+
+// NewCache returns a Cache in front of next reading the time from now, nil
+// for time.Now. The zero values of cfg take the defaults.
+func NewCache(next backend.TokenClient, cfg Config, now func() time.Time) *Cache {
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+
+	if cfg.NegativeTTL <= 0 {
+		cfg.NegativeTTL = DefaultNegativeTTL
+	}
+
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultMaxEntries
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+
+	return &Cache{
+		TokenClient: next,
+		now:         now,
+		entries:     make(map[[sha256.Size]byte]*list.Element),
+		lru:         list.New(),
+		cfg:         cfg,
+	}
+}
+
+// Check answers from the cache, or asks next and keeps its answer unless
+// the cache changed meanwhile. Errors are not kept.
+func (c *Cache) Check(ctx context.Context, token string) (check bool, err error) {
+	key := sha256.Sum256([]byte(token))
+
+	check, generation, ok := c.get(key)
+	if ok {
+		c.hits.Add(1)
+
+		return check, nil
+	}
+
+	c.misses.Add(1)
+
+	if check, err = c.TokenClient.Check(ctx, token); err != nil {
+		return false, err //nolint:wrapcheck
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation == c.generation {
+		c.set(key, check)
+	}
+
+	return check, nil
+}
+
+// Store forgets token before storing it with next, so a token revoked
+// before is not answered as such.
+func (c *Cache) Store(ctx context.Context, token string) (err error) {
+	c.Invalidate(token)
+
+	return c.TokenClient.Store(ctx, token) //nolint:wrapcheck
+}
+
+// Revoke revokes token with next and remembers it as revoked at once.
+func (c *Cache) Revoke(ctx context.Context, token string) (err error) {
+	if err = c.TokenClient.Revoke(ctx, token); err != nil {
+		c.Invalidate(token)
+
+		return err //nolint:wrapcheck
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	c.set(sha256.Sum256([]byte(token)), false)
+
+	return nil
+}
+
+// RevokeUser revokes the tokens of the user id with next. The cache does not
+// know whose tokens it keeps, so it forgets them all: every token is asked
+// to next again, a burst of misses after each log out of all devices or
+// account deletion.
+func (c *Cache) RevokeUser(ctx context.Context, id int) (err error) {
+	err = c.TokenClient.RevokeUser(ctx, id)
+
+	c.mutex.Lock()
+	c.generation++
+	c.entries = make(map[[sha256.Size]byte]*list.Element)
+	c.lru.Init()
+	c.mutex.Unlock()
+
+	return err //nolint:wrapcheck
+}
+
+// Invalidate forgets what the cache knows about token.
+func (c *Cache) Invalidate(token string) {
+	key := sha256.Sum256([]byte(token))
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// Stats returns the counters of the cache.
+func (c *Cache) Stats() (stats Stats) {
+	stats = Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+
+	if total := stats.Hits + stats.Misses; total != 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+
+	c.mutex.Lock()
+	stats.Entries = c.lru.Len()
+	c.mutex.Unlock()
+
+	return stats
+}
+
+// get returns the fresh answer kept for key, if any, and the generation of
+// the cache to ask next otherwise.
+func (c *Cache) get(key [sha256.Size]byte) (check bool, generation uint64, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return false, c.generation, false
+	}
+
+	cached, _ := element.Value.(*entry)
+	if !c.now().Before(cached.expires) {
+		c.remove(element)
+
+		return false, c.generation, false
+	}
+
+	c.lru.MoveToFront(element)
+
+	return cached.check, c.generation, true
+}
+
+// set keeps check for key, c.mutex must be held. A revoked token is never
+// answered as valid again before it is invalidated.
+func (c *Cache) set(key [sha256.Size]byte, check bool) {
+	ttl := c.cfg.TTL
+	if !check {
+		ttl = c.cfg.NegativeTTL
+	}
+
+	if element, ok := c.entries[key]; ok {
+		cached, _ := element.Value.(*entry)
+		if check && !cached.check {
+			return
+		}
+
+		cached.check, cached.expires = check, c.now().Add(ttl)
+		c.lru.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&entry{key: key, check: check, expires: c.now().Add(ttl)})
+
+	for c.lru.Len() > c.cfg.MaxEntries {
+		c.remove(c.lru.Back())
+		c.evictions.Add(1)
+	}
+}
+
+func (c *Cache) remove(element *list.Element) {
+	cached, _ := element.Value.(*entry)
+
+	delete(c.entries, cached.key)
+	c.lru.Remove(element)
+}
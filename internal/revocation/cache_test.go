@@ -0,0 +1,223 @@
+package revocation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"app/internal/backend"
+	"app/internal/revocation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTokensTest = errors.New("token service down")
+
+type tokensTest struct {
+	backend.TokenClient
+	valid map[string]bool
+	err   error
+	// answering runs once, between the answer of Check and its return.
+	answering func()
+	checks    int
+}
+
+func (tc *tokensTest) Check(_ context.Context, token string) (check bool, err error) {
+	tc.checks++
+
+	if tc.err != nil {
+		return false, tc.err
+	}
+
+	check = tc.valid[token]
+
+	if answering := tc.answering; answering != nil {
+		tc.answering = nil
+		answering()
+	}
+
+	return check, nil
+}
+
+func (tc *tokensTest) RevokeUser(_ context.Context, _ int) (err error) {
+	for token := range tc.valid {
+		delete(tc.valid, token)
+	}
+
+	return nil
+}
+
+func (tc *tokensTest) Store(_ context.Context, token string) (err error) {
+	tc.valid[token] = true
+
+	return nil
+}
+
+func (tc *tokensTest) Revoke(_ context.Context, token string) (err error) {
+	if tc.err != nil {
+		return tc.err
+	}
+
+	delete(tc.valid, token)
+
+	return nil
+}
+
+type clockTest struct {
+	now time.Time
+}
+
+func (c *clockTest) Now() time.Time {
+	return c.now
+}
+
+func newCacheTest(cfg revocation.Config) (cache *revocation.Cache, next *tokensTest, clock *clockTest) {
+	next = &tokensTest{valid: map[string]bool{"a": true, "b": true, "c": true}}
+	clock = &clockTest{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	return revocation.NewCache(next, cfg, clock.Now), next, clock
+}
+
+func TestCacheCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cache, next, clock := newCacheTest(revocation.Config{TTL: time.Second})
+
+	for i := 0; i < 3; i++ {
+		check, err := cache.Check(ctx, "a")
+		assert.Nil(t, err)
+		assert.True(t, check)
+	}
+
+	assert.Equal(t, 1, next.checks)
+	assert.Equal(t, revocation.Stats{Hits: 2, Misses: 1, HitRate: 2.0 / 3, Entries: 1}, cache.Stats())
+
+	clock.now = clock.now.Add(time.Second)
+	delete(next.valid, "a")
+
+	check, err := cache.Check(ctx, "a")
+	assert.Nil(t, err)
+	assert.False(t, check)
+	assert.Equal(t, 2, next.checks)
+}
+
+func TestCacheRevoke(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cache, next, clock := newCacheTest(revocation.Config{TTL: time.Second, NegativeTTL: time.Minute})
+
+	check, err := cache.Check(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, check)
+
+	assert.Nil(t, cache.Revoke(ctx, "a"))
+
+	clock.now = clock.now.Add(30 * time.Second)
+
+	check, err = cache.Check(ctx, "a")
+	assert.Nil(t, err)
+	assert.False(t, check)
+	assert.Equal(t, 1, next.checks)
+
+	assert.Nil(t, cache.Store(ctx, "a"))
+
+	check, err = cache.Check(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, check)
+	assert.Equal(t, 2, next.checks)
+}
+
+func TestCacheRevokeDuringCheck(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		revoke func(*revocation.Cache) error
+		name   string
+	}{
+		{
+			name:   "Revoke",
+			revoke: func(cache *revocation.Cache) error { return cache.Revoke(ctx, "a") },
+		},
+		{
+			name:   "RevokeUser",
+			revoke: func(cache *revocation.Cache) error { return cache.RevokeUser(ctx, 1) },
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cache, next, _ := newCacheTest(revocation.Config{})
+			next.answering = func() { assert.Nil(t, tt.revoke(cache)) }
+
+			// The answer asked before the revocation is not kept.
+			check, err := cache.Check(ctx, "a")
+			assert.Nil(t, err)
+			assert.True(t, check)
+
+			check, err = cache.Check(ctx, "a")
+			assert.Nil(t, err)
+			assert.False(t, check)
+		})
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cache, next, _ := newCacheTest(revocation.Config{})
+
+	_, err := cache.Check(ctx, "a")
+	assert.Nil(t, err)
+
+	delete(next.valid, "a")
+	cache.Invalidate("a")
+
+	check, err := cache.Check(ctx, "a")
+	assert.Nil(t, err)
+	assert.False(t, check)
+	assert.Equal(t, 2, next.checks)
+}
+
+func TestCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cache, next, _ := newCacheTest(revocation.Config{MaxEntries: 2})
+
+	for _, token := range []string{"a", "b", "a", "c", "a", "b"} {
+		_, err := cache.Check(ctx, token)
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, 4, next.checks)
+	assert.Equal(t, revocation.Stats{Hits: 2, Misses: 4, Evictions: 2, HitRate: 2.0 / 6, Entries: 2}, cache.Stats())
+}
+
+func TestCacheErrors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cache, next, _ := newCacheTest(revocation.Config{})
+	next.err = errTokensTest
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.Check(ctx, "a")
+		assert.ErrorIs(t, err, errTokensTest)
+	}
+
+	assert.ErrorIs(t, cache.Revoke(ctx, "a"), errTokensTest)
+	assert.Equal(t, 2, next.checks)
+
+	next.err = nil
+
+	check, err := cache.Check(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, check)
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// CORS configures the cross-origin requests browsers may make. The zero
+// value allows none.
+type CORS struct {
+	// AllowedOrigins are exact origins such as "https://app.example.com",
+	// patterns with one "*" for a subdomain such as "https://*.example.com",
+	// or "*" for any origin.
+	AllowedOrigins []string
+	// AllowedMethods defaults to GET, POST, PUT and DELETE.
+	AllowedMethods []string
+	// AllowedHeaders defaults to the headers the API reads.
+	AllowedHeaders []string
+	// ExposedHeaders defaults to the headers the API sets.
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Handler answers the preflights of the routes of router and adds the CORS
+// headers to the responses of allowed origins. Preflights for methods or
+// headers that are not allowed are refused with 403.
+func (c CORS) Handler(router *mux.Router) http.Handler {
+	methods := c.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}
+	}
+
+	headers := c.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Authorization", "Content-Type", "Accept", "X-CSRF-Token", "X-Request-ID"}
+	}
+
+	exposed := c.ExposedHeaders
+	if len(exposed) == 0 {
+		exposed = []string{"X-Request-ID", "X-CSRF-Token", "Deprecation", "Sunset", "Link", "WWW-Authenticate"}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			router.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if !c.allowsOrigin(origin) {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+
+				return
+			}
+
+			router.ServeHTTP(w, r)
+
+			return
+		}
+
+		if !preflight {
+			c.setOrigin(w, origin)
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(exposed, ", "))
+			router.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+		method := r.Header.Get("Access-Control-Request-Method")
+		requested := splitHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+
+		if !contains(methods, method) || !hasRoute(router, r, method) || !allContainedFold(headers, requested) {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		c.setOrigin(w, origin)
+		w.Header().Set("Access-Control-Allow-Methods", method)
+
+		if len(requested) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+		}
+
+		if c.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowsOrigin matches origin against AllowedOrigins.
+func (c CORS) allowsOrigin(origin string) (check bool) {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+
+		prefix, suffix, found := strings.Cut(strings.ToLower(allowed), "*")
+		if !found {
+			continue
+		}
+
+		origin := strings.ToLower(origin)
+		if len(origin) <= len(prefix)+len(suffix) ||
+			!strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+
+		// The wildcard stands for a single subdomain label, never for more
+		// levels, a port, a path or another scheme.
+		label := origin[len(prefix) : len(origin)-len(suffix)]
+		if strings.Trim(label, "abcdefghijklmnopqrstuvwxyz0123456789-") == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setOrigin allows origin, as "*" when any origin is allowed and no
+// credentials are, since browsers refuse "*" with credentials.
+func (c CORS) setOrigin(w http.ResponseWriter, origin string) {
+	value := origin
+	if contains(c.AllowedOrigins, "*") && !c.AllowCredentials {
+		value = "*"
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", value)
+
+	if c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// hasRoute reports whether router has a route for the path of r and method.
+func hasRoute(router *mux.Router, r *http.Request, method string) (check bool) {
+	var match mux.RouteMatch
+
+	probe := r.Clone(r.Context())
+	probe.Method = method
+
+	return router.Match(probe, &match) && match.MatchErr == nil
+}
+
+func splitHeaderList(value string) (list []string) {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
+
+func contains(list []string, value string) (check bool) {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(list []string, value string) (check bool) {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func allContainedFold(list, values []string) (check bool) {
+	for _, value := range values {
+		if !containsFold(list, value) {
+			return false
+		}
+	}
+
+	return true
+}
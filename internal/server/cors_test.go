@@ -0,0 +1,193 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"app/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	t.Parallel()
+
+	handler := server.NewHandler(serviceTest{}, nil, server.Config{
+		CORS: server.CORS{
+			AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+			AllowCredentials: true,
+			MaxAge:           10 * time.Minute,
+		},
+	})
+
+	for _, tt := range []struct {
+		name              string
+		inMethod          string
+		inPath            string
+		inOrigin          string
+		inRequestMethod   string
+		inRequestHeaders  string
+		outStatus         int
+		outAllowOrigin    string
+		outAllowMethods   string
+		outAllowHeaders   string
+		outMaxAge         string
+		outAllowCredTrue  bool
+		outExposedHeaders bool
+	}{
+		{
+			name:             "Preflight",
+			inMethod:         http.MethodOptions,
+			inPath:           "/v2/signin",
+			inOrigin:         "https://app.example.com",
+			inRequestMethod:  http.MethodPost,
+			inRequestHeaders: "content-type, x-request-id",
+			outStatus:        http.StatusNoContent,
+			outAllowOrigin:   "https://app.example.com",
+			outAllowMethods:  http.MethodPost,
+			outAllowHeaders:  "content-type, x-request-id",
+			outMaxAge:        "600",
+			outAllowCredTrue: true,
+		},
+		{
+			name:             "PreflightWildcard",
+			inMethod:         http.MethodOptions,
+			inPath:           "/users/1",
+			inOrigin:         "https://admin.example.org",
+			inRequestMethod:  http.MethodDelete,
+			inRequestHeaders: "Authorization",
+			outStatus:        http.StatusNoContent,
+			outAllowOrigin:   "https://admin.example.org",
+			outAllowMethods:  http.MethodDelete,
+			outAllowHeaders:  "Authorization",
+			outMaxAge:        "600",
+			outAllowCredTrue: true,
+		},
+		{
+			name:              "Request",
+			inMethod:          http.MethodPost,
+			inPath:            "/v2/signin",
+			inOrigin:          "https://app.example.com",
+			outStatus:         http.StatusBadRequest,
+			outAllowOrigin:    "https://app.example.com",
+			outAllowCredTrue:  true,
+			outExposedHeaders: true,
+		},
+		{
+			name:      "RequestWithoutOrigin",
+			inMethod:  http.MethodPost,
+			inPath:    "/v2/signin",
+			outStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "ErrorRequestOrigin",
+			inMethod:  http.MethodPost,
+			inPath:    "/v2/signin",
+			inOrigin:  "https://evil.example.net",
+			outStatus: http.StatusBadRequest,
+		},
+		{
+			name:            "ErrorPreflightOrigin",
+			inMethod:        http.MethodOptions,
+			inPath:          "/v2/signin",
+			inOrigin:        "https://evil.example.net",
+			inRequestMethod: http.MethodPost,
+			outStatus:       http.StatusForbidden,
+		},
+		{
+			name:            "ErrorPreflightWildcardPort",
+			inMethod:        http.MethodOptions,
+			inPath:          "/v2/signin",
+			inOrigin:        "https://evil.com:443.example.org",
+			inRequestMethod: http.MethodPost,
+			outStatus:       http.StatusForbidden,
+		},
+		{
+			name:            "ErrorPreflightWildcardLevels",
+			inMethod:        http.MethodOptions,
+			inPath:          "/v2/signin",
+			inOrigin:        "https://admin.eu.example.org",
+			inRequestMethod: http.MethodPost,
+			outStatus:       http.StatusForbidden,
+		},
+		{
+			name:            "ErrorPreflightWildcardApex",
+			inMethod:        http.MethodOptions,
+			inPath:          "/v2/signin",
+			inOrigin:        "https://example.org",
+			inRequestMethod: http.MethodPost,
+			outStatus:       http.StatusForbidden,
+		},
+		{
+			name:            "ErrorPreflightMethodNotRouted",
+			inMethod:        http.MethodOptions,
+			inPath:          "/v2/signin",
+			inOrigin:        "https://app.example.com",
+			inRequestMethod: http.MethodDelete,
+			outStatus:       http.StatusForbidden,
+		},
+		{
+			name:            "ErrorPreflightMethodNotAllowed",
+			inMethod:        http.MethodOptions,
+			inPath:          "/v2/signin",
+			inOrigin:        "https://app.example.com",
+			inRequestMethod: http.MethodPatch,
+			outStatus:       http.StatusForbidden,
+		},
+		{
+			name:             "ErrorPreflightHeader",
+			inMethod:         http.MethodOptions,
+			inPath:           "/v2/signin",
+			inOrigin:         "https://app.example.com",
+			inRequestMethod:  http.MethodPost,
+			inRequestHeaders: "X-Admin",
+			outStatus:        http.StatusForbidden,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.inMethod, tt.inPath, nil)
+			req.Header.Set("Origin", tt.inOrigin)
+			req.Header.Set("Access-Control-Request-Method", tt.inRequestMethod)
+			req.Header.Set("Access-Control-Request-Headers", tt.inRequestHeaders)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.outStatus, w.Code)
+			assert.Equal(t, tt.outAllowOrigin, w.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, tt.outAllowMethods, w.Header().Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, tt.outAllowHeaders, w.Header().Get("Access-Control-Allow-Headers"))
+			assert.Equal(t, tt.outMaxAge, w.Header().Get("Access-Control-Max-Age"))
+			assert.Equal(t, tt.outAllowCredTrue, w.Header().Get("Access-Control-Allow-Credentials") == "true")
+			assert.Equal(t, tt.outExposedHeaders, w.Header().Get("Access-Control-Expose-Headers") != "")
+
+			if tt.inOrigin != "" {
+				assert.Contains(t, w.Header().Values("Vary"), "Origin")
+			}
+		})
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	t.Parallel()
+
+	handler := server.NewHandler(serviceTest{}, nil, server.Config{
+		CORS: server.CORS{AllowedOrigins: []string{"*"}},
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/signin", nil)
+	req.Header.Set("Origin", "https://any.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
@@ -0,0 +1,110 @@
+package server_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"app/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name              string
+		in                server.SecurityHeaders
+		inTLS             bool
+		outFrameOptions   string
+		outReferrerPolicy string
+		outHSTS           string
+	}{
+		{
+			name:              "Defaults",
+			inTLS:             true,
+			outFrameOptions:   "DENY",
+			outReferrerPolicy: "no-referrer",
+		},
+		{
+			name: "HSTS",
+			in: server.SecurityHeaders{
+				HSTSMaxAge:            365 * 24 * time.Hour,
+				HSTSIncludeSubdomains: true,
+				HSTSPreload:           true,
+				FrameOptions:          "SAMEORIGIN",
+				ReferrerPolicy:        "same-origin",
+			},
+			inTLS:             true,
+			outFrameOptions:   "SAMEORIGIN",
+			outReferrerPolicy: "same-origin",
+			outHSTS:           "max-age=31536000; includeSubDomains; preload",
+		},
+		{
+			name:              "HSTSWithoutTLS",
+			in:                server.SecurityHeaders{HSTSMaxAge: time.Hour},
+			outFrameOptions:   "DENY",
+			outReferrerPolicy: "no-referrer",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/signin", nil)
+			if tt.inTLS {
+				req.TLS = &tls.ConnectionState{}
+			}
+
+			w := httptest.NewRecorder()
+			tt.in.Handler(http.NotFoundHandler()).ServeHTTP(w, req)
+
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.outFrameOptions, w.Header().Get("X-Frame-Options"))
+			assert.Equal(t, tt.outReferrerPolicy, w.Header().Get("Referrer-Policy"))
+			assert.Equal(t, tt.outHSTS, w.Header().Get("Strict-Transport-Security"))
+		})
+	}
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name        string
+		inPort      string
+		inHost      string
+		outLocation string
+	}{
+		{
+			name:        "DefaultPort",
+			inPort:      "443",
+			inHost:      "example.com:80",
+			outLocation: "https://example.com/v2/users?limit=1",
+		},
+		{
+			name:        "Port",
+			inPort:      "8443",
+			inHost:      "example.com",
+			outLocation: "https://example.com:8443/v2/users?limit=1",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/v2/users?limit=1", nil)
+			req.Host = tt.inHost
+
+			w := httptest.NewRecorder()
+			server.RedirectHTTPS(tt.inPort).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+			assert.Equal(t, tt.outLocation, w.Header().Get("Location"))
+		})
+	}
+}
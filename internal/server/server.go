@@ -0,0 +1,317 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"app/internal/endpoint"
+	"app/internal/entity"
+	"app/internal/service"
+	"app/internal/transport"
+	"app/internal/validation"
+
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
+)
+
+// Config holds the settings of the HTTP server itself.
+type Config struct {
+	Port string
+	// MaxBodyBytes bounds JSON request bodies, 0 means
+	// transport.DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+	// Deprecation and Sunset are the dates announced on the v1 routes, the
+	// zero value leaves Deprecation as "true" and omits Sunset.
+	Deprecation time.Time
+	Sunset      time.Time
+	// TokenHeader, TokenCookie and TokenQuery name where tokens are read
+	// from after "Authorization: Bearer", in this order. Empty ones are not
+	// read.
+	TokenHeader string
+	TokenCookie string
+	TokenQuery  string
+	// Session enables browser sessions, which replace TokenCookie by the
+	// cookie they set on sign-in.
+	Session transport.BrowserSession
+	// CORS lets pages of other origins call the API.
+	CORS CORS
+	// Security sets the headers added to every response.
+	Security SecurityHeaders
+	// TLSCertFile and TLSKeyFile enable HTTPS, RedirectPort then serves
+	// redirects from plain HTTP. The files are checked for changes every
+	// TLSReloadInterval.
+	TLSCertFile       string
+	TLSKeyFile        string
+	RedirectPort      string
+	TLSReloadInterval time.Duration
+	// MetricsPort serves the expvar metrics, such as the hit rate of the
+	// token cache, apart from the API.
+	MetricsPort string
+}
+
+// version is how the endpoints of one API version talk to their clients.
+type version struct {
+	encode httptransport.EncodeResponseFunc
+	before httptransport.RequestFunc
+	// profileMethod reads the profile, v1 kept the POST of the first release.
+	profileMethod string
+}
+
+type route struct {
+	handler      http.Handler
+	method, path string
+}
+
+// PrefixV1 and PrefixV2 start the paths of each API version. The paths
+// without prefix are aliases of v1.
+const (
+	PrefixV1 string = "/v1"
+	PrefixV2 string = "/v2"
+)
+
+// NewHandler routes the v1 API, with its legacy {"err": ...} bodies, under
+// /v1 and without prefix, and the v2 API, with problem details and DTOs,
+// under /v2.
+func NewHandler(svc service.Service, validator *validation.Validator, cfg Config) http.Handler {
+	v1 := version{
+		encode:        transport.EncodeResponse,
+		before:        transport.NegotiateErrorFormat,
+		profileMethod: http.MethodPost,
+	}
+	v2 := version{
+		encode:        transport.EncodeResponseV2,
+		before:        transport.ForceProblems,
+		profileMethod: http.MethodGet,
+	}
+
+	deprecated := Deprecated(cfg.Deprecation, cfg.Sunset)
+	router := mux.NewRouter()
+
+	for _, r := range routes(svc, validator, cfg, v2) {
+		router.Methods(r.method).Path(PrefixV2 + r.path).Handler(r.handler)
+	}
+
+	for _, r := range routes(svc, validator, cfg, v1) {
+		router.Methods(r.method).Path(PrefixV1 + r.path).Handler(deprecated(r.handler))
+		router.Methods(r.method).Path(r.path).Handler(deprecated(r.handler))
+	}
+
+	var handler http.Handler = router
+	if len(cfg.CORS.AllowedOrigins) != 0 {
+		handler = cfg.CORS.Handler(router)
+	}
+
+	return transport.RequestID(cfg.Security.Handler(handler))
+}
+
+// Deprecated is an HTTP middleware announcing that the v1 API is deprecated
+// (RFC 9745), when it stops being served (RFC 8594) and its successor.
+func Deprecated(deprecation, sunset time.Time) func(http.Handler) http.Handler {
+	value := "true"
+	if !deprecation.IsZero() {
+		value = "@" + strconv.FormatInt(deprecation.Unix(), 10)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", value)
+
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+
+			successor := PrefixV2 + strings.TrimPrefix(r.URL.Path, PrefixV1)
+			w.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// routes builds the handlers of every endpoint for one API version.
+func routes(svc service.Service, validator *validation.Validator, cfg Config, v version) []route {
+	options := []httptransport.ServerOption{
+		httptransport.ServerBefore(
+			httptransport.PopulateRequestContext,
+			v.before,
+			transport.ExtractToken(cfg.tokens()...),
+		),
+		httptransport.ServerErrorEncoder(transport.EncodeError),
+	}
+
+	newServer := func(e kitendpoint.Endpoint, decode httptransport.DecodeRequestFunc) http.Handler {
+		return httptransport.NewServer(e, decode, v.encode, options...)
+	}
+
+	signInEncode, logOutEncode := v.encode, v.encode
+	if cfg.Session.Cookie != "" {
+		signInEncode, logOutEncode = cfg.Session.Start(v.encode), cfg.Session.End(v.encode)
+	}
+
+	validate := endpoint.MakeValidationMiddleware(validator)
+	adminOnly := endpoint.MakeAuthorizationMiddleware(svc, entity.RoleAdmin)
+
+	return []route{
+		{
+			method: http.MethodPost,
+			path:   "/signup",
+			handler: newServer(
+				validate(endpoint.MakeSignUpEndpoint(svc)),
+				transport.DecodeRequestWithBody(entity.UsernamePasswordEmailRequest{}, cfg.MaxBodyBytes),
+			),
+		},
+		{
+			method: http.MethodPost,
+			path:   "/signin",
+			handler: httptransport.NewServer(
+				validate(endpoint.MakeSignInEndpoint(svc)),
+				transport.DecodeRequestWithBody(entity.UsernamePasswordRequest{}, cfg.MaxBodyBytes),
+				signInEncode,
+				options...,
+			),
+		},
+		{
+			method: http.MethodPost,
+			path:   "/logout",
+			handler: httptransport.NewServer(
+				endpoint.MakeLogOutEndpoint(svc),
+				transport.DecodeRequestWithHeader(entity.Token{}),
+				logOutEncode,
+				options...,
+			),
+		},
+		{
+			method: http.MethodPost,
+			path:   "/logout/all",
+			handler: httptransport.NewServer(
+				endpoint.MakeLogOutAllEndpoint(svc),
+				transport.DecodeRequestWithHeader(entity.Token{}),
+				logOutEncode,
+				options...,
+			),
+		},
+		{
+			method: http.MethodGet,
+			path:   "/users",
+			handler: newServer(
+				adminOnly(endpoint.MakeListUsersEndpoint(svc)),
+				transport.DecodeListUsersRequest(entity.ListUsersRequest{}),
+			),
+		},
+		{
+			method: http.MethodGet,
+			path:   "/users/export",
+			handler: httptransport.NewServer(
+				adminOnly(endpoint.MakeExportUsersEndpoint(svc)),
+				transport.DecodeRequestWithHeader(entity.Token{}),
+				transport.EncodeUsersExportResponse,
+				options...,
+			),
+		},
+		{
+			method: http.MethodPost,
+			path:   "/users/import",
+			handler: newServer(
+				adminOnly(endpoint.MakeImportUsersEndpoint(svc)),
+				transport.DecodeImportUsersRequest(entity.ImportUsersRequest{}, cfg.MaxBodyBytes),
+			),
+		},
+		{
+			method: http.MethodGet,
+			path:   "/users/{id:[0-9]+}",
+			handler: newServer(
+				adminOnly(endpoint.MakeGetUserEndpoint(svc)),
+				transport.DecodeRequestWithHeaderAndID(entity.TokenIDRequest{}),
+			),
+		},
+		{
+			method: http.MethodPost,
+			path:   "/users/{id:[0-9]+}/disable",
+			handler: newServer(
+				adminOnly(endpoint.MakeDisableUserEndpoint(svc)),
+				transport.DecodeRequestWithHeaderAndID(entity.TokenIDRequest{}),
+			),
+		},
+		{
+			method: http.MethodDelete,
+			path:   "/users/{id:[0-9]+}",
+			handler: newServer(
+				adminOnly(endpoint.MakeDeleteUserEndpoint(svc)),
+				transport.DecodeRequestWithHeaderAndID(entity.TokenIDRequest{}),
+			),
+		},
+		{
+			method: v.profileMethod,
+			path:   "/profile",
+			handler: newServer(
+				endpoint.MakeProfileEndpoint(svc),
+				transport.DecodeRequestWithHeader(entity.Token{}),
+			),
+		},
+		{
+			method: http.MethodDelete,
+			path:   "/profile",
+			handler: newServer(
+				endpoint.MakeDeleteAccountEndpoint(svc),
+				transport.DecodeRequestWithHeader(entity.Token{}),
+			),
+		},
+		{
+			method: http.MethodGet,
+			path:   "/sessions",
+			handler: newServer(
+				endpoint.MakeListSessionsEndpoint(svc),
+				transport.DecodeRequestWithHeader(entity.Token{}),
+			),
+		},
+		{
+			method: http.MethodDelete,
+			path:   "/sessions/{id:[0-9a-f]+}",
+			handler: newServer(
+				endpoint.MakeRevokeSessionEndpoint(svc),
+				transport.DecodeSessionRequest(entity.TokenSessionRequest{}),
+			),
+		},
+		{
+			method: http.MethodGet,
+			path:   "/profile/logins",
+			handler: newServer(
+				endpoint.MakeLoginHistoryEndpoint(svc),
+				transport.DecodeRequestWithHeader(entity.Token{}),
+			),
+		},
+		{
+			method: http.MethodPut,
+			path:   "/profile/password",
+			handler: newServer(
+				validate(endpoint.MakeChangePasswordEndpoint(svc)),
+				transport.DecodeChangePasswordRequest(entity.ChangePasswordRequest{}, cfg.MaxBodyBytes),
+			),
+		},
+	}
+}
+
+// tokens is the extractor chain configured by cfg.
+func (cfg Config) tokens() (extractors []transport.TokenExtractor) {
+	extractors = []transport.TokenExtractor{transport.BearerToken()}
+
+	if cfg.TokenHeader != "" {
+		extractors = append(extractors, transport.HeaderToken(cfg.TokenHeader))
+	}
+
+	switch {
+	case cfg.Session.Cookie != "":
+		extractors = append(extractors, cfg.Session.Token())
+	case cfg.TokenCookie != "":
+		extractors = append(extractors, transport.CookieToken(cfg.TokenCookie))
+	}
+
+	if cfg.TokenQuery != "" {
+		extractors = append(extractors, transport.QueryToken(cfg.TokenQuery))
+	}
+
+	return extractors
+}
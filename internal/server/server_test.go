@@ -0,0 +1,416 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/memory"
+	"app/internal/server"
+	"app/internal/service"
+	"app/internal/transport"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serviceTest answers like a backend where only mock.TokenTest is signed in.
+type serviceTest struct {
+	service.Service
+}
+
+func (serviceTest) SignIn(username, _ string, _ entity.Client) (string, error) {
+	if username != mock.UsernameTest {
+		return "", service.ErrCredentials
+	}
+
+	return mock.TokenTest, nil
+}
+
+func (serviceTest) Profile(token string) (entity.User, error) {
+	if token != mock.TokenTest {
+		return entity.User{}, service.ErrTokenNotValid
+	}
+
+	return entity.User{
+		ID:       mock.IDTest,
+		Username: mock.UsernameTest,
+		Password: mock.PasswordTest,
+		Email:    mock.EmailTest,
+		Role:     entity.RoleUser,
+	}, nil
+}
+
+func (serviceTest) LogOutAll(token string) error {
+	if token != mock.TokenTest {
+		return service.ErrTokenNotValid
+	}
+
+	return nil
+}
+
+func (serviceTest) ListSessions(token string) ([]entity.Session, error) {
+	if token != mock.TokenTest {
+		return nil, service.ErrTokenNotValid
+	}
+
+	return []entity.Session{{ID: "0a", Token: mock.TokenTest, Device: "Firefox on Linux", Current: true}}, nil
+}
+
+func (serviceTest) LoginHistory(token string) ([]entity.LoginAttempt, error) {
+	if token != mock.TokenTest {
+		return nil, service.ErrTokenNotValid
+	}
+
+	return []entity.LoginAttempt{{Method: entity.LoginMethodPassword, Device: "curl", Success: true}}, nil
+}
+
+func (serviceTest) RevokeSession(_, id string) error {
+	if id != "0a" {
+		return service.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+func (serviceTest) DeleteAccount(token string) error {
+	if token != mock.TokenTest {
+		return service.ErrTokenNotValid
+	}
+
+	return nil
+}
+
+func TestNewHandler(t *testing.T) {
+	t.Parallel()
+
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	handler := server.NewHandler(serviceTest{}, nil, server.Config{
+		Deprecation: time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:      sunset,
+	})
+
+	for _, tt := range []struct {
+		name           string
+		inMethod       string
+		inPath         string
+		inToken        string
+		inBody         string
+		outStatus      int
+		outBody        string
+		outContentType string
+		outChallenge   string
+		outDeprecated  bool
+	}{
+		{
+			name:          "V1SignIn",
+			inMethod:      http.MethodPost,
+			inPath:        "/v1/signin",
+			inBody:        `{"username":"username","password":"password"}`,
+			outStatus:     http.StatusOK,
+			outBody:       `{"token":"token"}`,
+			outDeprecated: true,
+		},
+		{
+			name:          "V1SignInError",
+			inMethod:      http.MethodPost,
+			inPath:        "/v1/signin",
+			inBody:        `{"username":"other","password":"password"}`,
+			outStatus:     http.StatusOK,
+			outBody:       `{"token":"","err":"invalid username or password"}`,
+			outDeprecated: true,
+		},
+		{
+			name:          "UnprefixedSignInError",
+			inMethod:      http.MethodPost,
+			inPath:        "/signin",
+			inBody:        `{"username":"other","password":"password"}`,
+			outStatus:     http.StatusOK,
+			outBody:       `{"token":"","err":"invalid username or password"}`,
+			outDeprecated: true,
+		},
+		{
+			name:      "UnprefixedProfile",
+			inMethod:  http.MethodPost,
+			inPath:    "/profile",
+			inToken:   mock.TokenTest,
+			outStatus: http.StatusOK,
+			outBody: `{"user":{"id":1,"username":"username","password":"password","email":"email@email.com",` +
+				`"role":"user"}}`,
+			outDeprecated: true,
+		},
+		{
+			name:      "V2SignIn",
+			inMethod:  http.MethodPost,
+			inPath:    "/v2/signin",
+			inBody:    `{"username":"username","password":"password"}`,
+			outStatus: http.StatusOK,
+			outBody:   `{"token":"token"}`,
+		},
+		{
+			name:           "V2SignInError",
+			inMethod:       http.MethodPost,
+			inPath:         "/v2/signin",
+			inBody:         `{"username":"other","password":"password"}`,
+			outStatus:      http.StatusUnauthorized,
+			outContentType: transport.ContentTypeProblem,
+			outBody: `{"type":"/problems/invalid-credentials","title":"Invalid username or password",` +
+				`"status":401,"detail":"invalid username or password","instance":"request-id"}`,
+		},
+		{
+			name:           "V2MalformedBody",
+			inMethod:       http.MethodPost,
+			inPath:         "/v2/signin",
+			inBody:         `{"admin":true}`,
+			outStatus:      http.StatusBadRequest,
+			outContentType: transport.ContentTypeProblem,
+			outBody: `{"type":"/problems/malformed-body","title":"Malformed request body","status":400,` +
+				`"detail":"failed to decode request: unknown field \"admin\"","instance":"request-id"}`,
+		},
+		{
+			name:      "V2Profile",
+			inMethod:  http.MethodGet,
+			inPath:    "/v2/profile",
+			inToken:   mock.TokenTest,
+			outStatus: http.StatusOK,
+			outBody:   `{"id":1,"username":"username","email":"email@email.com","role":"user","disabled":false}`,
+		},
+		{
+			name:      "V2ProfileBearer",
+			inMethod:  http.MethodGet,
+			inPath:    "/v2/profile",
+			inToken:   "Bearer " + mock.TokenTest,
+			outStatus: http.StatusOK,
+			outBody:   `{"id":1,"username":"username","email":"email@email.com","role":"user","disabled":false}`,
+		},
+		{
+			name:           "V2ProfileMissingToken",
+			inMethod:       http.MethodGet,
+			inPath:         "/v2/profile",
+			outStatus:      http.StatusUnauthorized,
+			outContentType: transport.ContentTypeProblem,
+			outChallenge:   "Bearer",
+			outBody: `{"type":"/problems/missing-token","title":"Missing token","status":401,` +
+				`"detail":"failed to get header","instance":"request-id"}`,
+		},
+		{
+			name:      "V2ProfilePost",
+			inMethod:  http.MethodPost,
+			inPath:    "/v2/profile",
+			inToken:   mock.TokenTest,
+			outStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:      "V2DeleteAccount",
+			inMethod:  http.MethodDelete,
+			inPath:    "/v2/profile",
+			inToken:   mock.TokenTest,
+			outStatus: http.StatusNoContent,
+		},
+		{
+			name:      "V2LogOutAll",
+			inMethod:  http.MethodPost,
+			inPath:    "/v2/logout/all",
+			inToken:   mock.TokenTest,
+			outStatus: http.StatusNoContent,
+		},
+		{
+			name:      "V2Sessions",
+			inMethod:  http.MethodGet,
+			inPath:    "/v2/sessions",
+			inToken:   mock.TokenTest,
+			outStatus: http.StatusOK,
+			outBody: `{"sessions":[{"createdAt":"0001-01-01T00:00:00Z","lastSeen":"0001-01-01T00:00:00Z",` +
+				`"id":"0a","ip":"","userAgent":"","device":"Firefox on Linux","current":true}]}`,
+		},
+		{
+			name:      "V2LoginHistory",
+			inMethod:  http.MethodGet,
+			inPath:    "/v2/profile/logins",
+			inToken:   mock.TokenTest,
+			outStatus: http.StatusOK,
+			outBody: `{"logins":[{"time":"0001-01-01T00:00:00Z","method":"password","ip":"","userAgent":"",` +
+				`"device":"curl","success":true}]}`,
+		},
+		{
+			name:      "V2RevokeSession",
+			inMethod:  http.MethodDelete,
+			inPath:    "/v2/sessions/0a",
+			inToken:   mock.TokenTest,
+			outStatus: http.StatusNoContent,
+		},
+		{
+			name:           "V2RevokeSessionNotFound",
+			inMethod:       http.MethodDelete,
+			inPath:         "/v2/sessions/0b",
+			inToken:        mock.TokenTest,
+			outStatus:      http.StatusNotFound,
+			outContentType: transport.ContentTypeProblem,
+			outBody: `{"type":"/problems/session-not-found","title":"Session not found","status":404,` +
+				`"detail":"session not found","instance":"request-id"}`,
+		},
+		{
+			name:           "V2DeleteAccountError",
+			inMethod:       http.MethodDelete,
+			inPath:         "/v2/profile",
+			inToken:        "other",
+			outStatus:      http.StatusUnauthorized,
+			outContentType: transport.ContentTypeProblem,
+			outChallenge:   `Bearer error="invalid_token", error_description="invalid token"`,
+			outBody: `{"type":"/problems/invalid-token","title":"Invalid token","status":401,` +
+				`"detail":"token not validate","instance":"request-id"}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.inMethod, tt.inPath, strings.NewReader(tt.inBody))
+			req.Header.Set(transport.HeaderRequestID, "request-id")
+
+			if tt.inToken != "" {
+				req.Header.Set("Authorization", tt.inToken)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.outStatus, w.Code)
+
+			if tt.outBody != "" {
+				assert.JSONEq(t, tt.outBody, w.Body.String())
+			}
+
+			if tt.outContentType != "" {
+				assert.Equal(t, tt.outContentType, w.Header().Get("Content-Type"))
+			}
+
+			assert.Equal(t, tt.outChallenge, w.Header().Get("WWW-Authenticate"))
+
+			if !tt.outDeprecated {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+				assert.Empty(t, w.Header().Get("Sunset"))
+
+				return
+			}
+
+			assert.Equal(t, "@1782864000", w.Header().Get("Deprecation"))
+			assert.Equal(t, sunset.Format(http.TimeFormat), w.Header().Get("Sunset"))
+			assert.Equal(
+				t,
+				"</v2"+strings.TrimPrefix(tt.inPath, "/v1")+`>; rel="successor-version"`,
+				w.Header().Get("Link"),
+			)
+		})
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	t.Parallel()
+
+	handler := server.Deprecated(time.Time{}, time.Time{})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Equal(t, `</v2/users>; rel="successor-version"`, w.Header().Get("Link"))
+}
+
+func TestNewHandlerBrowserSession(t *testing.T) {
+	t.Parallel()
+
+	handler := server.NewHandler(serviceTest{}, nil, server.Config{
+		Session: transport.BrowserSession{Cookie: "session"},
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(
+		http.MethodPost,
+		"/v2/signin",
+		strings.NewReader(`{"username":"username","password":"password"}`),
+	))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	cookies := w.Result().Cookies()
+	csrf := w.Header().Get("X-CSRF-Token")
+
+	assert.Len(t, cookies, 2)
+	assert.NotEmpty(t, csrf)
+
+	for _, tt := range []struct {
+		name      string
+		inAuth    string
+		inCSRF    string
+		outStatus int
+	}{
+		{
+			name:      "Cookie",
+			inCSRF:    csrf,
+			outStatus: http.StatusNoContent,
+		},
+		{
+			name:      "Bearer",
+			inAuth:    "Bearer " + mock.TokenTest,
+			outStatus: http.StatusNoContent,
+		},
+		{
+			name:      "ErrorCSRF",
+			outStatus: http.StatusForbidden,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodDelete, "/v2/profile", nil)
+			req.Header.Set("Authorization", tt.inAuth)
+			req.Header.Set("X-CSRF-Token", tt.inCSRF)
+
+			for _, cookie := range cookies {
+				req.AddCookie(cookie)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.outStatus, w.Code)
+		})
+	}
+}
+
+func TestNewHandlerV1WithoutPasswords(t *testing.T) {
+	t.Parallel()
+
+	svc := service.NewServiceWithBackends(memory.NewStorage(), memory.NewTokens(nil, 0), &service.InfoServices{
+		Secret: mock.SecretTest,
+	})
+
+	token, err := svc.SignUp(mock.UsernameTest, mock.PasswordTest, mock.EmailTest, entity.Client{})
+	assert.Nil(t, err)
+	assert.Nil(t, svc.PromoteAdmins([]int{mock.IDTest}))
+
+	handler := server.NewHandler(svc, nil, server.Config{})
+
+	// v1 reads the profile with POST.
+	for path, method := range map[string]string{
+		"/v1/profile": http.MethodPost,
+		"/v1/users":   http.MethodGet,
+		"/v1/users/1": http.MethodGet,
+	} {
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Contains(t, w.Body.String(), `"username":"username","password":""`, path)
+		assert.NotContains(t, w.Body.String(), "$argon2id$", path)
+	}
+}
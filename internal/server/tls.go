@@ -0,0 +1,173 @@
+package server
+
+import (
+	"crypto/tls"
+	"expvar"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+
+	defaultCertReloadInterval = 10 * time.Second
+)
+
+// CertReloader serves a certificate and key pair from files, loading them
+// again when they change so renewed certificates need no restart.
+type CertReloader struct {
+	cert              atomic.Pointer[tls.Certificate]
+	checked           atomic.Int64
+	certMod, keyMod   time.Time
+	certFile, keyFile string
+	interval          time.Duration
+	mutex             sync.Mutex
+}
+
+// NewCertReloader loads the pair of certFile and keyFile, which are checked
+// for changes every interval, 0 for 10 seconds.
+func NewCertReloader(certFile, keyFile string, interval time.Duration) (reloader *CertReloader, err error) {
+	if interval <= 0 {
+		interval = defaultCertReloadInterval
+	}
+
+	reloader = &CertReloader{certFile: certFile, keyFile: keyFile, interval: interval}
+
+	if reloader.certMod, reloader.keyMod, err = modTimes(certFile, keyFile); err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	reloader.cert.Store(&cert)
+	reloader.checked.Store(time.Now().UnixNano())
+
+	return reloader, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate. It serves the loaded
+// pair, and the first handshake after interval checks the files.
+func (c *CertReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	now, checked := time.Now().UnixNano(), c.checked.Load()
+
+	if now-checked >= int64(c.interval) && c.checked.CompareAndSwap(checked, now) {
+		c.reload()
+	}
+
+	return c.cert.Load(), nil
+}
+
+// reload loads the files again when they were modified, and keeps the
+// previous pair if they are not a valid one yet, such as while they are
+// being written. Invalid files are only reported once until they change.
+func (c *CertReloader) reload() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	certMod, keyMod, err := modTimes(c.certFile, c.keyFile)
+	if err != nil || (certMod.Equal(c.certMod) && keyMod.Equal(c.keyMod)) {
+		return
+	}
+
+	c.certMod, c.keyMod = certMod, keyMod
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		log.Println("keeping the previous certificate:", err)
+
+		return
+	}
+
+	c.cert.Store(&cert)
+}
+
+// RedirectHTTPS answers every request with a permanent redirect to the same
+// URL over HTTPS on port, "" or "443" for the default one.
+func RedirectHTTPS(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// ListenAndServe serves handler on cfg.Port, over TLS when cfg.TLSCertFile
+// and cfg.TLSKeyFile are set. cfg.RedirectPort then serves the redirects of
+// plain HTTP requests to HTTPS, and cfg.MetricsPort the expvar metrics.
+func ListenAndServe(cfg Config, handler http.Handler) (err error) {
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	if cfg.MetricsPort != "" {
+		metrics := &http.Server{
+			Addr:              ":" + cfg.MetricsPort,
+			Handler:           expvar.Handler(),
+			ReadHeaderTimeout: readHeaderTimeout,
+		}
+
+		go func() {
+			log.Println(metrics.ListenAndServe())
+		}()
+	}
+
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		return fmt.Errorf("failed to serve: %w", srv.ListenAndServe())
+	}
+
+	reloader, err := NewCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSReloadInterval)
+	if err != nil {
+		return err
+	}
+
+	srv.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.RedirectPort != "" {
+		redirect := &http.Server{
+			Addr:              ":" + cfg.RedirectPort,
+			Handler:           RedirectHTTPS(cfg.Port),
+			ReadHeaderTimeout: readHeaderTimeout,
+		}
+
+		go func() {
+			log.Println(redirect.ListenAndServe())
+		}()
+	}
+
+	return fmt.Errorf("failed to serve: %w", srv.ListenAndServeTLS("", ""))
+}
+
+func modTimes(certFile, keyFile string) (certMod, keyMod time.Time, err error) {
+	certInfo, err := os.Stat(certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat certificate: %w", err)
+	}
+
+	keyInfo, err := os.Stat(keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat key: %w", err)
+	}
+
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
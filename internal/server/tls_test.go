@@ -0,0 +1,88 @@
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"app/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+	_, err := server.NewCertReloader(certFile, keyFile, time.Nanosecond)
+	assert.ErrorContains(t, err, "failed to load certificate")
+
+	writeCertTest(t, certFile, keyFile, "first", time.Now().Add(-time.Hour))
+
+	reloader, err := server.NewCertReloader(certFile, keyFile, time.Nanosecond)
+	assert.Nil(t, err)
+	assert.Equal(t, "first", leafCommonName(t, reloader))
+
+	// Between two checks the loaded pair is served.
+	slow, err := server.NewCertReloader(certFile, keyFile, time.Hour)
+	assert.Nil(t, err)
+
+	writeCertTest(t, certFile, keyFile, "second", time.Now())
+	assert.Equal(t, "second", leafCommonName(t, reloader))
+	assert.Equal(t, "first", leafCommonName(t, slow))
+
+	// A pair being written is not valid yet, the previous one stays.
+	assert.Nil(t, os.WriteFile(keyFile, []byte("partial"), 0o600))
+	assert.Nil(t, os.Chtimes(keyFile, time.Now().Add(time.Hour), time.Now().Add(time.Hour)))
+	assert.Equal(t, "second", leafCommonName(t, reloader))
+}
+
+func leafCommonName(t *testing.T, reloader *server.CertReloader) string {
+	t.Helper()
+
+	cert, err := reloader.GetCertificate(nil)
+	assert.Nil(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.Nil(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+// writeCertTest writes a self-signed pair for name, modified at mod.
+func writeCertTest(t *testing.T, certFile, keyFile, name string, mod time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	assert.Nil(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.Nil(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	for _, file := range []string{certFile, keyFile} {
+		assert.Nil(t, os.Chtimes(file, mod, mod))
+	}
+}
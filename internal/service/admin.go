@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"app/internal/backend"
+	"app/internal/entity"
+)
+
+// Authorize validates the token and checks that its owner has one of the
+// given roles. Without roles any valid token is accepted.
+func (s *service) Authorize(token string, roles ...string) (claims entity.Claims, err error) {
+	claims, err = s.claims(token)
+	if err != nil {
+		return entity.Claims{}, err
+	}
+
+	if len(roles) == 0 {
+		return claims, nil
+	}
+
+	for _, role := range roles {
+		if claims.Role == role {
+			return claims, nil
+		}
+	}
+
+	return entity.Claims{}, ErrForbidden
+}
+
+// GetUser ...
+func (s *service) GetUser(id int) (user entity.User, err error) {
+	if user, err = s.storage.GetUserByID(context.Background(), id); err != nil {
+		return entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	user.Role = s.roleOf(user)
+	user.Password = ""
+
+	return user, nil
+}
+
+// DisableUser marks the user as disabled in the storage, so every gateway
+// instance refuses its sign-ins and tokens. Storages that keep no flag fail
+// with ErrNotSupported.
+func (s *service) DisableUser(id int) (err error) {
+	if err = s.storage.SetDisabled(context.Background(), id, true); err != nil {
+		if errors.Is(err, backend.ErrNotSupported) {
+			return fmt.Errorf("%w:%s", ErrNotSupported, err.Error())
+		}
+
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	return nil
+}
+
+// DeleteUser revokes the tokens of the user, then deletes it.
+func (s *service) DeleteUser(id int) (err error) {
+	ctx := context.Background()
+
+	if err = s.revokeUserTokens(ctx, id); err != nil {
+		return err
+	}
+
+	if err = s.storage.DeleteUser(ctx, id); err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	return nil
+}
+
+// PromoteAdmins gives the admin role to the existing users ids. Admins are
+// named by ID, which is never given again, as usernames can be registered by
+// anyone once free.
+func (s *service) PromoteAdmins(ids []int) (err error) {
+	for _, id := range ids {
+		if err = s.storage.SetRole(context.Background(), id, entity.RoleAdmin); err != nil {
+			if errors.Is(err, backend.ErrNotSupported) {
+				return fmt.Errorf("%w:%s", ErrNotSupported, err.Error())
+			}
+
+			return fmt.Errorf("%w:admin %d: %s", ErrWebServer, id, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// roleOf returns the role stored with the user, users of storages that keep
+// no roles are not admins.
+func (s *service) roleOf(user entity.User) (role string) {
+	if user.Role == "" {
+		return entity.RoleUser
+	}
+
+	return user.Role
+}
@@ -0,0 +1,275 @@
+package service_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/memory"
+	"app/internal/service"
+
+	httpMock "app/internal/service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const adminUsernameTest string = "admin"
+
+func getAdminInfoServices() *service.InfoServices {
+	return &service.InfoServices{
+		DBHost:    mock.DBHostTest,
+		DBPort:    mock.PortTest,
+		TokenHost: mock.TokenHostTest,
+		TokenPort: mock.PortTest,
+		Secret:    mock.SecretTest,
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name         string
+		inUsername   string
+		inTokenRole  string
+		inStoredRole string
+		inRoles      []string
+		outRole      string
+		outErr       error
+		outCheck     bool
+	}{
+		{
+			name:       "NoErrorWithoutRoles",
+			inUsername: mock.UsernameTest,
+			outRole:    entity.RoleUser,
+			outCheck:   true,
+		},
+		{
+			name:         "NoErrorAdminFromStorage",
+			inUsername:   mock.UsernameTest,
+			inStoredRole: entity.RoleAdmin,
+			inRoles:      []string{entity.RoleAdmin},
+			outRole:      entity.RoleAdmin,
+			outCheck:     true,
+		},
+		{
+			name:        "ErrorAdminOnlyInToken",
+			inUsername:  mock.UsernameTest,
+			inTokenRole: entity.RoleAdmin,
+			inRoles:     []string{entity.RoleAdmin},
+			outErr:      service.ErrForbidden,
+			outCheck:    true,
+		},
+		{
+			name:       "ErrorAdminUsername",
+			inUsername: adminUsernameTest,
+			inRoles:    []string{entity.RoleAdmin},
+			outErr:     service.ErrForbidden,
+			outCheck:   true,
+		},
+		{
+			name:         "ErrorTokenNotValid",
+			inUsername:   mock.UsernameTest,
+			inStoredRole: entity.RoleAdmin,
+			inRoles:      []string{entity.RoleAdmin},
+			outErr:       service.ErrTokenNotValid,
+			outCheck:     false,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			responseJSON := fmt.Sprintf(`{
+					"id":1,
+					"username":%q,
+					"email":"email@email.com",
+					"role":%q,
+					"user":{"id":1,"username":%q,"role":%q},
+					"check":%t
+				}`, tt.inUsername, tt.inTokenRole, tt.inUsername, tt.inStoredRole, tt.outCheck)
+
+			//nolint:bodyclose
+			svc := service.NewService(
+				httpMock.NewMockClient(getMock(responseJSON)),
+				getAdminInfoServices(),
+			)
+
+			claims, err := svc.Authorize(mock.TokenTest, tt.inRoles...)
+
+			if tt.outErr != nil {
+				assert.ErrorIs(t, err, tt.outErr)
+				assert.Zero(t, claims)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, mock.IDTest, claims.ID)
+				assert.Equal(t, tt.outRole, claims.Role)
+			}
+		})
+	}
+}
+
+func TestPromoteAdmins(t *testing.T) {
+	t.Parallel()
+
+	svc := service.NewServiceWithBackends(memory.NewStorage(), memory.NewTokens(nil, 0), getAdminInfoServices())
+
+	token, err := svc.SignUp(adminUsernameTest, mock.PasswordTest, mock.EmailTest, entity.Client{})
+	assert.Nil(t, err)
+
+	_, err = svc.Authorize(token, entity.RoleAdmin)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+
+	assert.ErrorIs(t, svc.PromoteAdmins([]int{2}), service.ErrWebServer)
+	assert.Nil(t, svc.PromoteAdmins([]int{mock.IDTest}))
+
+	// The tokens issued before are admin ones too.
+	claims, err := svc.Authorize(token, entity.RoleAdmin)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.RoleAdmin, claims.Role)
+
+	user, err := svc.GetUser(mock.IDTest)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.RoleAdmin, user.Role)
+}
+
+func TestDisableUser(t *testing.T) {
+	t.Parallel()
+
+	storage := memory.NewStorage()
+	svc := service.NewServiceWithBackends(storage, memory.NewTokens(nil, 0), getAdminInfoServices())
+
+	_, err := svc.SignUp(mock.UsernameTest, mock.PasswordTest, mock.EmailTest, entity.Client{})
+	assert.Nil(t, err)
+
+	token, err := svc.SignIn(mock.UsernameTest, mock.PasswordTest, entity.Client{})
+	assert.Nil(t, err)
+
+	user, err := svc.GetUser(mock.IDTest)
+	assert.Nil(t, err)
+	assert.False(t, user.Disabled)
+
+	assert.Nil(t, svc.DisableUser(mock.IDTest))
+
+	// Another gateway instance on the same storage sees the flag.
+	other := service.NewServiceWithBackends(storage, memory.NewTokens(nil, 0), getAdminInfoServices())
+
+	user, err = other.GetUser(mock.IDTest)
+	assert.Nil(t, err)
+	assert.True(t, user.Disabled)
+
+	_, err = other.SignIn(mock.UsernameTest, mock.PasswordTest, entity.Client{})
+	assert.ErrorIs(t, err, service.ErrUserDisabled)
+
+	_, err = svc.Profile(token)
+	assert.ErrorIs(t, err, service.ErrUserDisabled)
+
+	assert.Nil(t, svc.DeleteUser(mock.IDTest))
+
+	_, err = svc.GetUser(mock.IDTest)
+	assert.ErrorIs(t, err, service.ErrWebServer)
+}
+
+func TestAdminErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name                 string
+		url                  string
+		method               string
+		isErrorInsideRequest bool
+	}{
+		{
+			name:   "ErrorGetUser",
+			url:    "http://db:8080/user/id",
+			method: http.MethodGet,
+		},
+		{
+			name:                 "ErrorInsideGetUser",
+			url:                  "http://db:8080/user/id",
+			method:               http.MethodGet,
+			isErrorInsideRequest: true,
+		},
+		{
+			name:   "ErrorSetDisabled",
+			url:    "http://db:8080/user/disabled",
+			method: http.MethodPut,
+		},
+		{
+			name:                 "ErrorInsideSetDisabled",
+			url:                  "http://db:8080/user/disabled",
+			method:               http.MethodPut,
+			isErrorInsideRequest: true,
+		},
+		{
+			name:   "ErrorRevokeUser",
+			url:    "http://token:8080/tokens",
+			method: http.MethodDelete,
+		},
+		{
+			name:   "ErrorDeleteUser",
+			url:    "http://db:8080/user",
+			method: http.MethodDelete,
+		},
+		{
+			name:                 "ErrorInsideDeleteUser",
+			url:                  "http://db:8080/user",
+			method:               http.MethodDelete,
+			isErrorInsideRequest: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			//nolint:bodyclose
+			svc := service.NewService(
+				httpMock.NewMockClient(getIsErrorMock(
+					tt.isErrorInsideRequest,
+					newErrorHTTPComponets(tt.url, tt.method),
+					`{"user":{"id":1}}`,
+				)),
+				getAdminInfoServices(),
+			)
+
+			var err error
+
+			switch tt.method {
+			case http.MethodGet:
+				_, err = svc.GetUser(mock.IDTest)
+			case http.MethodPut:
+				err = svc.DisableUser(mock.IDTest)
+			default:
+				err = svc.DeleteUser(mock.IDTest)
+			}
+
+			assert.ErrorIs(t, err, service.ErrWebServer)
+		})
+	}
+}
+
+func TestAdminNotSupported(t *testing.T) {
+	t.Parallel()
+
+	svc := service.NewService(
+		httpMock.NewMockClient(func(_ *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusMethodNotAllowed,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}),
+		getAdminInfoServices(),
+	)
+
+	err := svc.DisableUser(mock.IDTest)
+	assert.ErrorIs(t, err, service.ErrNotSupported)
+	assert.NotErrorIs(t, err, service.ErrWebServer)
+
+	err = svc.PromoteAdmins([]int{mock.IDTest})
+	assert.ErrorIs(t, err, service.ErrNotSupported)
+	assert.NotErrorIs(t, err, service.ErrWebServer)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"app/internal/entity"
+)
+
+// ExportPageSize is the number of users requested per page while exporting.
+const ExportPageSize int = 500
+
+// ExportUsers calls yield for every user ordered by ID. Users are fetched a
+// page at a time so memory stays bounded when the storage service pages; the
+// export stops as soon as ctx is done or yield fails.
+func (s *service) ExportUsers(ctx context.Context, yield func(entity.User) error) (err error) {
+	options := entity.ListUsersOptions{Sort: SortByID, Limit: ExportPageSize}
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return fmt.Errorf("export canceled: %w", err)
+		}
+
+		page, paged, err := s.storage.ListUsers(ctx, options)
+		if err != nil {
+			return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+		}
+
+		users := page.Users
+
+		if !paged {
+			// The storage service ignored the options and sent every user.
+			sort.Slice(users, func(i, j int) bool {
+				return users[i].ID < users[j].ID
+			})
+
+			page.NextCursor = ""
+		}
+
+		for _, user := range users {
+			if err = ctx.Err(); err != nil {
+				return fmt.Errorf("export canceled: %w", err)
+			}
+
+			user.Role = s.roleOf(user)
+
+			if err = yield(user); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+
+		options.Cursor = page.NextCursor
+	}
+}
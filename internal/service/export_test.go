@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/service"
+
+	httpMock "app/internal/service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportUsers(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		in     map[string]string
+		outIDs []int
+		outErr error
+	}{
+		{
+			name: "NoErrorWithoutBackendPaging",
+			in: map[string]string{
+				"": `{"users":[{"id":2},{"id":1},{"id":3}]}`,
+			},
+			outIDs: []int{1, 2, 3},
+		},
+		{
+			name: "NoErrorWithBackendPaging",
+			in: map[string]string{
+				"":     `{"paged":true,"nextCursor":"next","users":[{"id":1},{"id":2}]}`,
+				"next": `{"paged":true,"users":[{"id":3}]}`,
+			},
+			outIDs: []int{1, 2, 3},
+		},
+		{
+			name: "ErrorInsideRequest",
+			in: map[string]string{
+				"": `{"err":"error"}`,
+			},
+			outErr: service.ErrWebServer,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ids []int
+
+			mockHTTP := httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					Body: io.NopCloser(strings.NewReader(tt.in[req.URL.Query().Get("cursor")])),
+				}, nil
+			})
+
+			svc := service.NewService(mockHTTP, getAdminInfoServices())
+
+			err := svc.ExportUsers(context.TODO(), func(user entity.User) error {
+				assert.Equal(t, entity.RoleUser, user.Role)
+
+				ids = append(ids, user.ID)
+
+				return nil
+			})
+
+			if tt.outErr != nil {
+				assert.ErrorIs(t, err, tt.outErr)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			assert.Equal(t, tt.outIDs, ids)
+		})
+	}
+}
+
+func TestExportUsersCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+
+	//nolint:bodyclose
+	svc := service.NewService(
+		httpMock.NewMockClient(getMock(`{"users":[{"id":1},{"id":2}]}`)),
+		getAdminInfoServices(),
+	)
+
+	err := svc.ExportUsers(ctx, func(_ entity.User) error {
+		cancel()
+
+		return nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
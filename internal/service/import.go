@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"app/internal/backend"
+	"app/internal/entity"
+	"app/internal/password"
+)
+
+// Bounds of entity.ImportOptions.Concurrency.
+const (
+	DefaultImportConcurrency int = 4
+	MaxImportConcurrency     int = 32
+)
+
+var (
+	ErrInvalidRow       = errors.New("invalid row")
+	ErrDuplicateRow     = errors.New("duplicate username in import")
+	ErrUserAlreadyExist = errors.New("user already exists")
+)
+
+// ImportUsers creates the users of rows through the storage service, at most
+// options.Concurrency at a time. Rows that fail validation are reported as
+// failed, rows whose username already exists are skipped. With
+// options.DryRun nothing is created.
+func (s *service) ImportUsers(
+	ctx context.Context,
+	rows []entity.ImportRow,
+	options entity.ImportOptions,
+) (report entity.ImportReport, err error) {
+	var (
+		seen    = make(map[string]struct{}, len(rows))
+		users   = make([]entity.UsernamePasswordEmailRequest, len(rows))
+		pending = make(chan int)
+		wg      sync.WaitGroup
+	)
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultImportConcurrency
+	}
+
+	if concurrency > MaxImportConcurrency {
+		concurrency = MaxImportConcurrency
+	}
+
+	report = entity.ImportReport{
+		Results: make([]entity.ImportResult, len(rows)),
+		DryRun:  options.DryRun,
+	}
+
+	for i, row := range rows {
+		report.Results[i] = entity.ImportResult{Line: row.Line, Username: row.User.Username}
+	}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range pending {
+				report.Results[i].Status, report.Results[i].Err = s.importUser(ctx, users[i], options.DryRun)
+			}
+		}()
+	}
+
+	for i, row := range rows {
+		if users[i], err = s.validateImportRow(row); err != nil {
+			report.Results[i].Status, report.Results[i].Err = entity.ImportFailed, err.Error()
+
+			continue
+		}
+
+		report.Results[i].Username = users[i].Username
+
+		if _, ok := seen[users[i].Username]; ok {
+			report.Results[i].Status, report.Results[i].Err = entity.ImportSkipped, ErrDuplicateRow.Error()
+
+			continue
+		}
+
+		seen[users[i].Username] = struct{}{}
+		pending <- i
+	}
+
+	close(pending)
+	wg.Wait()
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case entity.ImportCreated:
+			report.Created++
+		case entity.ImportSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+//nolint:revive
+func (s *service) importUser(
+	ctx context.Context,
+	user entity.UsernamePasswordEmailRequest,
+	dryRun bool,
+) (status, errMessage string) {
+	if err := ctx.Err(); err != nil {
+		return entity.ImportFailed, err.Error()
+	}
+
+	id, err := s.storage.GetIDByUsername(ctx, user.Username)
+	if err != nil && !errors.Is(err, backend.ErrResponse) {
+		return entity.ImportFailed, fmt.Errorf("%w:%s", ErrWebServer, err.Error()).Error()
+	}
+
+	if err == nil && id != 0 {
+		return entity.ImportSkipped, ErrUserAlreadyExist.Error()
+	}
+
+	// Rows may carry hashes exported from another system, those are kept and
+	// upgraded on the user's next sign in.
+	plain := !password.IsHash(user.Password)
+
+	if plain {
+		if err := s.policy.Check(user.Password, user.Username, user.Email); err != nil {
+			return entity.ImportFailed, err.Error()
+		}
+	}
+
+	if dryRun {
+		return entity.ImportCreated, ""
+	}
+
+	if plain {
+		hashed, err := s.hasher.Hash(user.Password)
+		if err != nil {
+			return entity.ImportFailed, err.Error()
+		}
+
+		user.Password = hashed
+	}
+
+	if err := s.storage.CreateUser(ctx, user); err != nil {
+		return entity.ImportFailed, fmt.Errorf("%w:%s", ErrWebServer, err.Error()).Error()
+	}
+
+	return entity.ImportCreated, ""
+}
+
+// validateImportRow checks row like a sign-up and returns its user
+// normalized.
+func (s *service) validateImportRow(
+	row entity.ImportRow,
+) (user entity.UsernamePasswordEmailRequest, err error) {
+	if row.Err != "" {
+		return user, fmt.Errorf("%w: %s", ErrInvalidRow, row.Err)
+	}
+
+	normalized, err := s.validator.Validate(row.User)
+	if err != nil {
+		return user, fmt.Errorf("%w: %s", ErrInvalidRow, err.Error())
+	}
+
+	user, _ = normalized.(entity.UsernamePasswordEmailRequest)
+
+	return user, nil
+}
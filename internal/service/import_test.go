@@ -0,0 +1,116 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/service"
+
+	httpMock "app/internal/service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportUsers(t *testing.T) {
+	t.Parallel()
+
+	rows := []entity.ImportRow{
+		{Line: 2, User: entity.UsernamePasswordEmailRequest{Username: "new", Password: "p", Email: "new@email.com"}},
+		{Line: 3, User: entity.UsernamePasswordEmailRequest{Username: "taken", Password: "p", Email: "t@email.com"}},
+		{Line: 4, User: entity.UsernamePasswordEmailRequest{Username: "new", Password: "p", Email: "new@email.com"}},
+		{Line: 5, User: entity.UsernamePasswordEmailRequest{Username: "bad", Password: "p", Email: "not-an-email"}},
+		{Line: 6, Err: "malformed json"},
+		{Line: 7, User: entity.UsernamePasswordEmailRequest{Username: "broken", Password: "p", Email: "b@email.com"}},
+	}
+
+	for _, tt := range []struct {
+		name       string
+		inDryRun   bool
+		outCreated int
+		outPosts   int32
+		outStatus  []string
+	}{
+		{
+			name:       mock.NameNoError,
+			outCreated: 1,
+			outPosts:   2,
+			outStatus: []string{
+				entity.ImportCreated,
+				entity.ImportSkipped,
+				entity.ImportSkipped,
+				entity.ImportFailed,
+				entity.ImportFailed,
+				entity.ImportFailed,
+			},
+		},
+		{
+			name:       mock.NameNoError + "DryRun",
+			inDryRun:   true,
+			outCreated: 2,
+			outStatus: []string{
+				entity.ImportCreated,
+				entity.ImportSkipped,
+				entity.ImportSkipped,
+				entity.ImportFailed,
+				entity.ImportFailed,
+				entity.ImportCreated,
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var posts int32
+
+			mockHTTP := httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				body, _ := io.ReadAll(req.Body)
+				response := `{"id":0,"err":"not found"}`
+
+				switch {
+				case req.Method == http.MethodGet && strings.Contains(string(body), "taken"):
+					response = `{"id":2}`
+				case req.Method == http.MethodPost:
+					atomic.AddInt32(&posts, 1)
+
+					response = `{}`
+
+					if strings.Contains(string(body), "broken") {
+						response = `{"err":"error"}`
+					}
+				}
+
+				return &http.Response{Body: io.NopCloser(strings.NewReader(response))}, nil
+			})
+
+			svc := service.NewService(mockHTTP, getAdminInfoServices())
+
+			report, err := svc.ImportUsers(
+				context.TODO(),
+				rows,
+				entity.ImportOptions{DryRun: tt.inDryRun, Concurrency: 2},
+			)
+			assert.Nil(t, err)
+
+			status := make([]string, 0, len(report.Results))
+			for i, result := range report.Results {
+				assert.Equal(t, rows[i].Line, result.Line)
+
+				status = append(status, result.Status)
+			}
+
+			assert.Equal(t, tt.outStatus, status)
+			assert.Equal(t, tt.outCreated, report.Created)
+			assert.Equal(t, 2, report.Skipped)
+			assert.Equal(t, len(rows)-2-tt.outCreated, report.Failed)
+			assert.Equal(t, tt.inDryRun, report.DryRun)
+			assert.Equal(t, tt.outPosts, atomic.LoadInt32(&posts))
+		})
+	}
+}
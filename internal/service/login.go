@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"app/internal/entity"
+)
+
+// LoginHistory returns the sign-in attempts of the owner of token, the last
+// one first.
+func (s *service) LoginHistory(token string) (attempts []entity.LoginAttempt, err error) {
+	claims, err := s.claims(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if attempts, err = s.logins.ListLogins(context.Background(), claims.ID); err != nil {
+		return nil, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	return attempts, nil
+}
+
+// notifyTimeout bounds the notice of a sign-in from a new device.
+const notifyTimeout = 30 * time.Second
+
+// recordLogin appends the sign-in of user from client, failed with failure
+// unless it is nil. A successful one from a device the user never signed in
+// from before is sent to the notifier in the background, its errors are only
+// logged.
+func (s *service) recordLogin(ctx context.Context, user entity.User, client entity.Client, failure error) (err error) {
+	attempt := entity.LoginAttempt{
+		UserID:      user.ID,
+		Time:        time.Now(),
+		Method:      entity.LoginMethodPassword,
+		IP:          client.IP,
+		UserAgent:   client.UserAgent,
+		Device:      deviceLabel(client.UserAgent),
+		Fingerprint: deviceFingerprint(client.UserAgent),
+		Success:     failure == nil,
+	}
+
+	if failure != nil {
+		attempt.Reason = loginReason(failure)
+	}
+
+	var newDevice bool
+
+	if attempt.Success && s.notifier != nil {
+		if newDevice, err = s.isNewDevice(ctx, user.ID, attempt.Fingerprint); err != nil {
+			return err
+		}
+	}
+
+	if err = s.logins.AppendLogin(ctx, attempt); err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	if newDevice {
+		go s.notifyNewDevice(user, attempt)
+	}
+
+	return nil
+}
+
+// notifyNewDevice sends the notice of attempt to the notifier, apart from the
+// sign-in so a slow notifier does not hold it.
+func (s *service) notifyNewDevice(user entity.User, attempt entity.LoginAttempt) {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	if err := s.notifier.NotifyNewDevice(ctx, user, attempt); err != nil {
+		log.Printf("failed to notify user %d of a new device: %s", user.ID, err)
+	}
+}
+
+// loginReason returns the reason recorded for a sign-in failed with failure,
+// whose text is not shown to the user.
+func loginReason(failure error) (reason string) {
+	switch {
+	case errors.Is(failure, ErrCredentials):
+		return entity.LoginReasonInvalidCredentials
+	case errors.Is(failure, ErrUserDisabled):
+		return entity.LoginReasonUserDisabled
+	default:
+		return entity.LoginReasonError
+	}
+}
+
+// isNewDevice reports whether the user signed in before, but never from the
+// device with fingerprint. The first sign-in has nothing to compare with.
+func (s *service) isNewDevice(ctx context.Context, userID int, fingerprint string) (check bool, err error) {
+	attempts, err := s.logins.ListLogins(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	var signedIn bool
+
+	for _, attempt := range attempts {
+		if !attempt.Success {
+			continue
+		}
+
+		if attempt.Fingerprint == fingerprint {
+			return false, nil
+		}
+
+		signedIn = true
+	}
+
+	return signedIn, nil
+}
+
+// deviceFingerprint tells devices apart by their user agent, the IP changes
+// with the network.
+func deviceFingerprint(userAgent string) (fingerprint string) {
+	sum := sha256.Sum256([]byte(userAgent))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/memory"
+	"app/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// notifierTest sends the attempts it is told about to attempts, when given
+// a context with a deadline.
+type notifierTest struct {
+	attempts chan entity.LoginAttempt
+}
+
+func (n *notifierTest) NotifyNewDevice(ctx context.Context, _ entity.User, attempt entity.LoginAttempt) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return context.DeadlineExceeded
+	}
+
+	n.attempts <- attempt
+
+	return nil
+}
+
+func TestLoginHistory(t *testing.T) {
+	t.Parallel()
+
+	notifier := &notifierTest{attempts: make(chan entity.LoginAttempt, 3)}
+	svc := service.NewServiceWithBackends(memory.NewStorage(), memory.NewTokens(nil, 0), &service.InfoServices{
+		Secret:   mock.SecretTest,
+		Notifier: notifier,
+	})
+
+	token, err := svc.SignUp(mock.UsernameTest, mock.PasswordTest, mock.EmailTest, entity.Client{})
+	assert.Nil(t, err)
+
+	laptop := entity.Client{IP: "10.0.0.1", UserAgent: firefoxTest}
+	phone := entity.Client{IP: "10.0.0.2", UserAgent: iPhoneTest}
+
+	_, err = svc.SignIn("unknown", mock.PasswordTest, laptop)
+	assert.ErrorIs(t, err, service.ErrCredentials)
+
+	_, err = svc.SignIn(mock.UsernameTest, "wrong", phone)
+	assert.ErrorIs(t, err, service.ErrCredentials)
+
+	for _, client := range []entity.Client{laptop, laptop, phone} {
+		_, err = svc.SignIn(mock.UsernameTest, mock.PasswordTest, client)
+		assert.Nil(t, err)
+	}
+
+	select {
+	case attempt := <-notifier.attempts:
+		assert.Equal(t, "Safari on iOS", attempt.Device)
+	case <-time.After(time.Second):
+		t.Fatal("no notice of the new device")
+	}
+
+	logins, err := svc.LoginHistory(token)
+	assert.Nil(t, err)
+	assert.Len(t, logins, 4)
+
+	for i, want := range []struct {
+		ip      string
+		success bool
+	}{{"10.0.0.2", true}, {"10.0.0.1", true}, {"10.0.0.1", true}, {"10.0.0.2", false}} {
+		assert.Equal(t, want.ip, logins[i].IP)
+		assert.Equal(t, want.success, logins[i].Success)
+		assert.Equal(t, entity.LoginMethodPassword, logins[i].Method)
+	}
+
+	assert.Equal(t, entity.LoginReasonInvalidCredentials, logins[3].Reason)
+	assert.Empty(t, notifier.attempts)
+
+	_, err = svc.LoginHistory("other")
+	assert.ErrorIs(t, err, service.ErrTokenNotValid)
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"app/internal/entity"
+)
+
+// Accepted values of entity.ListUsersOptions.Sort, a leading "-" reverses
+// the order.
+const (
+	SortByID       string = "id"
+	SortByUsername string = "username"
+
+	DefaultLimit int = 100
+	MaxLimit     int = 1000
+)
+
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+type cursor struct {
+	Sort     string `json:"s"`
+	Username string `json:"u,omitempty"`
+	ID       int    `json:"i"`
+}
+
+// ListUsers returns one page of users. The options are forwarded to the
+// storage service; when it does not answer with a paged response the
+// filtering, sorting and slicing are done here.
+func (s *service) ListUsers(options entity.ListUsersOptions) (page entity.UsersPage, err error) {
+	if options, err = normalizeListOptions(options); err != nil {
+		return entity.UsersPage{}, err
+	}
+
+	page, paged, err := s.storage.ListUsers(context.Background(), options)
+	if err != nil {
+		return entity.UsersPage{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	if paged {
+		page.Users = withoutPasswords(page.Users)
+
+		return page, nil
+	}
+
+	return PaginateUsers(withoutPasswords(page.Users), options)
+}
+
+// PaginateUsers filters, sorts and slices users in memory.
+func PaginateUsers(users []entity.User, options entity.ListUsersOptions) (page entity.UsersPage, err error) {
+	if options, err = normalizeListOptions(options); err != nil {
+		return entity.UsersPage{}, err
+	}
+
+	filtered := make([]entity.User, 0, len(users))
+
+	for _, user := range users {
+		if matchListOptions(user, options) {
+			filtered = append(filtered, user)
+		}
+	}
+
+	less := userLess(options.Sort)
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return less(filtered[i], filtered[j])
+	})
+
+	total := len(filtered)
+
+	start := options.Offset
+
+	if options.Cursor != "" {
+		after, err := decodeCursor(options.Cursor, options.Sort)
+		if err != nil {
+			return entity.UsersPage{}, err
+		}
+
+		start = sort.Search(len(filtered), func(i int) bool {
+			return less(after, filtered[i])
+		})
+	}
+
+	if start > len(filtered) {
+		start = len(filtered)
+	}
+
+	end := start + options.Limit
+	if end > len(filtered) {
+		end = len(filtered)
+	}
+
+	page = entity.UsersPage{
+		Users: filtered[start:end],
+		Total: &total,
+	}
+
+	if end < len(filtered) && end > start {
+		page.NextCursor = encodeCursor(filtered[end-1], options.Sort)
+	}
+
+	return page, nil
+}
+
+func normalizeListOptions(options entity.ListUsersOptions) (entity.ListUsersOptions, error) {
+	switch strings.TrimPrefix(options.Sort, "-") {
+	case "":
+		options.Sort = SortByID
+	case SortByID, SortByUsername:
+	default:
+		return entity.ListUsersOptions{}, fmt.Errorf("%w: unknown sort %q", ErrInvalidListOptions, options.Sort)
+	}
+
+	if options.Limit < 0 || options.Offset < 0 {
+		return entity.ListUsersOptions{}, fmt.Errorf("%w: limit and offset must be positive", ErrInvalidListOptions)
+	}
+
+	if options.Limit == 0 {
+		options.Limit = DefaultLimit
+	}
+
+	if options.Limit > MaxLimit {
+		options.Limit = MaxLimit
+	}
+
+	return options, nil
+}
+
+func matchListOptions(user entity.User, options entity.ListUsersOptions) bool {
+	if options.UsernamePrefix != "" &&
+		!strings.HasPrefix(strings.ToLower(user.Username), strings.ToLower(options.UsernamePrefix)) {
+		return false
+	}
+
+	if options.EmailDomain != "" {
+		at := strings.LastIndex(user.Email, "@")
+		if at < 0 || !strings.EqualFold(user.Email[at+1:], strings.TrimPrefix(options.EmailDomain, "@")) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func userLess(sortBy string) func(a, b entity.User) bool {
+	var less func(a, b entity.User) bool
+
+	switch strings.TrimPrefix(sortBy, "-") {
+	case SortByUsername:
+		less = func(a, b entity.User) bool {
+			if a.Username != b.Username {
+				return a.Username < b.Username
+			}
+
+			return a.ID < b.ID
+		}
+	default:
+		less = func(a, b entity.User) bool {
+			return a.ID < b.ID
+		}
+	}
+
+	if strings.HasPrefix(sortBy, "-") {
+		return func(a, b entity.User) bool {
+			return less(b, a)
+		}
+	}
+
+	return less
+}
+
+func encodeCursor(user entity.User, sortBy string) string {
+	c := cursor{Sort: sortBy, ID: user.ID}
+
+	if strings.TrimPrefix(sortBy, "-") == SortByUsername {
+		c.Username = user.Username
+	}
+
+	//nolint:errchkjson
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded, sortBy string) (user entity.User, err error) {
+	var c cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("%w: malformed cursor", ErrInvalidListOptions)
+	}
+
+	if err = json.Unmarshal(data, &c); err != nil {
+		return entity.User{}, fmt.Errorf("%w: malformed cursor", ErrInvalidListOptions)
+	}
+
+	if c.Sort != sortBy {
+		return entity.User{}, fmt.Errorf("%w: cursor was issued for another sort", ErrInvalidListOptions)
+	}
+
+	return entity.User{ID: c.ID, Username: c.Username}, nil
+}
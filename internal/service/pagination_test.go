@@ -0,0 +1,209 @@
+package service_test
+
+import (
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/service"
+
+	httpMock "app/internal/service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getPaginationUsers() []entity.User {
+	return []entity.User{
+		{ID: 3, Username: "carol", Email: "carol@example.com"},
+		{ID: 1, Username: "alice", Email: "alice@example.com"},
+		{ID: 4, Username: "alan", Email: "alan@other.org"},
+		{ID: 2, Username: "bob", Email: "bob@Example.com"},
+	}
+}
+
+func usersIDs(users []entity.User) []int {
+	ids := make([]int, 0, len(users))
+
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	return ids
+}
+
+func TestPaginateUsers(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name      string
+		in        entity.ListUsersOptions
+		outErr    error
+		outIDs    []int
+		outTotal  int
+		outCursor bool
+	}{
+		{
+			name:     "NoErrorDefault",
+			in:       entity.ListUsersOptions{},
+			outIDs:   []int{1, 2, 3, 4},
+			outTotal: 4,
+		},
+		{
+			name:      "NoErrorOffset",
+			in:        entity.ListUsersOptions{Offset: 1, Limit: 2},
+			outIDs:    []int{2, 3},
+			outTotal:  4,
+			outCursor: true,
+		},
+		{
+			name:     "NoErrorOffsetOutOfRange",
+			in:       entity.ListUsersOptions{Offset: 10},
+			outIDs:   []int{},
+			outTotal: 4,
+		},
+		{
+			name:     "NoErrorSortUsernameDesc",
+			in:       entity.ListUsersOptions{Sort: "-username"},
+			outIDs:   []int{3, 2, 1, 4},
+			outTotal: 4,
+		},
+		{
+			name:     "NoErrorUsernamePrefix",
+			in:       entity.ListUsersOptions{UsernamePrefix: "AL", Sort: "username"},
+			outIDs:   []int{4, 1},
+			outTotal: 2,
+		},
+		{
+			name:     "NoErrorEmailDomain",
+			in:       entity.ListUsersOptions{EmailDomain: "@example.com"},
+			outIDs:   []int{1, 2, 3},
+			outTotal: 3,
+		},
+		{
+			name:   "ErrorSort",
+			in:     entity.ListUsersOptions{Sort: "email"},
+			outErr: service.ErrInvalidListOptions,
+		},
+		{
+			name:   "ErrorNegativeOffset",
+			in:     entity.ListUsersOptions{Offset: -1},
+			outErr: service.ErrInvalidListOptions,
+		},
+		{
+			name:   "ErrorCursor",
+			in:     entity.ListUsersOptions{Cursor: "%%"},
+			outErr: service.ErrInvalidListOptions,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			page, err := service.PaginateUsers(getPaginationUsers(), tt.in)
+			if tt.outErr != nil {
+				assert.ErrorIs(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.outIDs, usersIDs(page.Users))
+			assert.Equal(t, tt.outTotal, *page.Total)
+			assert.Equal(t, tt.outCursor, page.NextCursor != "")
+		})
+	}
+}
+
+func TestPaginateUsersCursor(t *testing.T) {
+	t.Parallel()
+
+	var ids []int
+
+	options := entity.ListUsersOptions{Sort: "-username", Limit: 1}
+
+	for {
+		page, err := service.PaginateUsers(getPaginationUsers(), options)
+		assert.Nil(t, err)
+
+		ids = append(ids, usersIDs(page.Users)...)
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		options.Cursor = page.NextCursor
+	}
+
+	assert.Equal(t, []int{3, 2, 1, 4}, ids)
+
+	options.Sort = "id"
+
+	_, err := service.PaginateUsers(getPaginationUsers(), options)
+	assert.ErrorIs(t, err, service.ErrInvalidListOptions)
+}
+
+func TestListUsers(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name      string
+		in        string
+		outIDs    []int
+		outTotal  int
+		outCursor string
+	}{
+		{
+			name: "NoErrorGatewayPaging",
+			in: `{"users":[
+				{"id":2,"username":"bob"},
+				{"id":1,"username":"alice"},
+				{"id":3,"username":"carol"}
+			]}`,
+			outIDs:   []int{1, 2},
+			outTotal: 3,
+		},
+		{
+			name: "NoErrorBackendPaging",
+			in: `{"paged":true,"nextCursor":"next","total":7,"users":[
+				{"id":5,"username":"eve"},
+				{"id":6,"username":"frank"}
+			]}`,
+			outIDs:    []int{5, 6},
+			outTotal:  7,
+			outCursor: "next",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			//nolint:bodyclose
+			svc := service.NewService(
+				httpMock.NewMockClient(getMock(tt.in)),
+				getAdminInfoServices(),
+			)
+
+			page, err := svc.ListUsers(entity.ListUsersOptions{Limit: 2})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.outIDs, usersIDs(page.Users))
+			assert.Equal(t, tt.outTotal, *page.Total)
+
+			if tt.outCursor != "" {
+				assert.Equal(t, tt.outCursor, page.NextCursor)
+			} else {
+				assert.NotEmpty(t, page.NextCursor)
+			}
+		})
+	}
+
+	//nolint:bodyclose
+	svc := service.NewService(
+		httpMock.NewMockClient(getMock(`{"err":"error"}`)),
+		getAdminInfoServices(),
+	)
+
+	_, err := svc.ListUsers(entity.ListUsersOptions{})
+	assert.ErrorIs(t, err, service.ErrWebServer)
+
+	_, err = svc.ListUsers(entity.ListUsersOptions{Limit: -1})
+	assert.ErrorIs(t, err, service.ErrInvalidListOptions)
+}
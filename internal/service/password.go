@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"app/internal/backend"
+	"app/internal/entity"
+	"app/internal/password"
+)
+
+// ChangePassword replaces the password of the token's user after checking
+// the current one and the policy.
+func (s *service) ChangePassword(token, oldPassword, newPassword string) (err error) {
+	claims, err := s.claims(token)
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.verifyCredentials(claims.Username, oldPassword); err != nil {
+		return err
+	}
+
+	if err = s.policy.Check(newPassword, claims.Username, claims.Email); err != nil {
+		return err
+	}
+
+	return s.upgradePassword(claims.ID, newPassword)
+}
+
+// verifyCredentials looks the user up by username and checks plain against
+// the stored hash. Users stored before the gateway hashed passwords are
+// checked by the storage service instead. Either way the stored password is
+// replaced by a hash with the current parameters when it is not one already,
+// a failed upgrade is only logged. user is returned with the errors of
+// known usernames too, for their login history.
+func (s *service) verifyCredentials(username, plain string) (user entity.User, err error) {
+	ctx := context.Background()
+
+	id, err := s.storage.GetIDByUsername(ctx, username)
+	if err != nil {
+		return entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	if id == 0 {
+		return entity.User{}, ErrCredentials
+	}
+
+	if user, err = s.storage.GetUserByID(ctx, id); err != nil {
+		return entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	if password.IsHash(user.Password) {
+		ok, err := s.hasher.Verify(user.Password, plain)
+		if err != nil {
+			return user, fmt.Errorf("%w: %s", ErrCredentials, err.Error())
+		}
+
+		if !ok {
+			return user, ErrCredentials
+		}
+	} else if err = s.verifyStoredPlain(username, plain); err != nil {
+		return user, err
+	}
+
+	if s.hasher.NeedsRehash(user.Password) {
+		if err = s.upgradePassword(user.ID, plain); err != nil {
+			log.Printf("failed to upgrade password hash of user %d: %s", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+// verifyStoredPlain asks the storage service to compare a password it keeps
+// as is. A refusal of the storage service is a wrong password, only the
+// failures to reach it are errors of the web server.
+func (s *service) verifyStoredPlain(username, plain string) (err error) {
+	if _, err = s.storage.GetUserByCredentials(context.Background(), username, plain); err != nil {
+		if errors.Is(err, backend.ErrResponse) {
+			return ErrCredentials
+		}
+
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	return nil
+}
+
+// upgradePassword stores a hash of plain made with the current parameters.
+// The storage service must expose PUT /user/password for it.
+func (s *service) upgradePassword(id int, plain string) (err error) {
+	encoded, err := s.hasher.Hash(plain)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err = s.storage.UpdatePassword(context.Background(), id, encoded); err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	return nil
+}
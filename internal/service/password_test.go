@@ -0,0 +1,237 @@
+package service_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/password"
+	"app/internal/service"
+
+	httpMock "app/internal/service/mock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Hash of mock.PasswordTest written by passlib.
+const legacyHashTest string = "$pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M"
+
+func getCurrentHashTest(t *testing.T) string {
+	t.Helper()
+
+	encoded, err := password.NewHasher(password.Params{}, "").Hash(mock.PasswordTest)
+	assert.Nil(t, err)
+
+	return encoded
+}
+
+func TestSignInLegacyHash(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name       string
+		inStored   string
+		inPassword string
+		inPlain    string
+		outErr     error
+		outUpgrade bool
+	}{
+		{
+			name:       mock.NameNoError,
+			inStored:   legacyHashTest,
+			inPassword: mock.PasswordTest,
+			outUpgrade: true,
+		},
+		{
+			name:       mock.NameNoError + "Current",
+			inStored:   getCurrentHashTest(t),
+			inPassword: mock.PasswordTest,
+		},
+		{
+			name:       "ErrorWrongPassword",
+			inStored:   legacyHashTest,
+			inPassword: "wrong",
+			outErr:     service.ErrCredentials,
+		},
+		{
+			name:       "ErrorPlainRejectedByStorage",
+			inStored:   mock.PasswordTest,
+			inPassword: mock.PasswordTest,
+			inPlain:    `{"err":"invalid username or password"}`,
+			outErr:     service.ErrCredentials,
+		},
+		{
+			name:       "ErrorPlainStorageDown",
+			inStored:   mock.PasswordTest,
+			inPassword: mock.PasswordTest,
+			inPlain:    `<html>bad gateway</html>`,
+			outErr:     service.ErrWebServer,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mutex    sync.Mutex
+				upgraded entity.IDPasswordRequest
+			)
+
+			mockHTTP := httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				response := `{"token":"token","check":true}`
+
+				switch req.URL.Path {
+				case "/user/username_password":
+					response = tt.inPlain
+				case "/id/username":
+					response = `{"id":1}`
+				case "/user/id":
+					user, _ := json.Marshal(entity.User{ID: 1, Username: mock.UsernameTest, Password: tt.inStored})
+					response = `{"user":` + string(user) + `}`
+				case "/user/password":
+					mutex.Lock()
+					_ = json.NewDecoder(req.Body).Decode(&upgraded)
+					mutex.Unlock()
+
+					response = `{}`
+				}
+
+				return &http.Response{Body: io.NopCloser(strings.NewReader(response))}, nil
+			})
+
+			svc := service.NewService(mockHTTP, getAdminInfoServices())
+
+			token, err := svc.SignIn(mock.UsernameTest, tt.inPassword, entity.Client{})
+
+			if tt.outErr != nil {
+				assert.ErrorIs(t, err, tt.outErr)
+				assert.Empty(t, token)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, mock.TokenTest, token)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if !tt.outUpgrade {
+				assert.Zero(t, upgraded)
+
+				return
+			}
+
+			ok, err := password.NewHasher(password.Params{}, "").Verify(upgraded.Password, tt.inPassword)
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, mock.IDTest, upgraded.ID)
+			assert.False(t, password.NewHasher(password.Params{}, "").NeedsRehash(upgraded.Password))
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name          string
+		inOldPassword string
+		inNewPassword string
+		outErr        error
+	}{
+		{
+			name:          mock.NameNoError,
+			inOldPassword: mock.PasswordTest,
+			inNewPassword: "battery-staple-97",
+		},
+		{
+			name:          "ErrorWrongPassword",
+			inOldPassword: "wrong",
+			inNewPassword: "battery-staple-97",
+			outErr:        service.ErrCredentials,
+		},
+		{
+			name:          "ErrorPolicy",
+			inOldPassword: mock.PasswordTest,
+			inNewPassword: "01234",
+			outErr:        password.ErrWeakPassword,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mutex   sync.Mutex
+				changed entity.IDPasswordRequest
+			)
+
+			stored := getCurrentHashTest(t)
+
+			mockHTTP := httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				response := `{"id":1,"username":"username","email":"email@email.com","check":true}`
+
+				switch req.URL.Path {
+				case "/user/id":
+					user, _ := json.Marshal(entity.User{ID: 1, Username: mock.UsernameTest, Password: stored})
+					response = `{"user":` + string(user) + `}`
+				case "/user/password":
+					mutex.Lock()
+					_ = json.NewDecoder(req.Body).Decode(&changed)
+					mutex.Unlock()
+
+					response = `{}`
+				}
+
+				return &http.Response{Body: io.NopCloser(strings.NewReader(response))}, nil
+			})
+
+			infoServices := getAdminInfoServices()
+			infoServices.PasswordPolicy = password.DefaultPolicy()
+
+			err := service.NewService(mockHTTP, infoServices).ChangePassword(
+				mock.TokenTest,
+				tt.inOldPassword,
+				tt.inNewPassword,
+			)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if tt.outErr != nil {
+				assert.ErrorIs(t, err, tt.outErr)
+				assert.Zero(t, changed)
+
+				return
+			}
+
+			ok, err := password.NewHasher(password.Params{}, "").Verify(changed.Password, tt.inNewPassword)
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, mock.IDTest, changed.ID)
+		})
+	}
+}
+
+func TestSignUpPolicy(t *testing.T) {
+	t.Parallel()
+
+	infoServices := getAdminInfoServices()
+	infoServices.PasswordPolicy = password.DefaultPolicy()
+
+	//nolint:bodyclose
+	svc := service.NewService(httpMock.NewMockClient(getMock(`{"token":"token"}`)), infoServices)
+
+	token, err := svc.SignUp(mock.UsernameTest, "01234", mock.EmailTest, entity.Client{})
+	assert.ErrorIs(t, err, password.ErrWeakPassword)
+	assert.ErrorContains(t, err, "must be at least 8 characters")
+	assert.Empty(t, token)
+
+	token, err = svc.SignUp(mock.UsernameTest, "battery-staple-97", mock.EmailTest, entity.Client{})
+	assert.Nil(t, err)
+	assert.Equal(t, mock.TokenTest, token)
+}
@@ -1,12 +1,21 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"net/http"
+	"log"
+	"strings"
+	"time"
 
+	"app/internal/backend"
+	"app/internal/claims"
 	"app/internal/entity"
+	"app/internal/memory"
+	"app/internal/notify"
+	"app/internal/password"
 	"app/internal/petition"
+	"app/internal/validation"
 )
 
 type InfoServices struct {
@@ -15,234 +24,275 @@ type InfoServices struct {
 	TokenHost string
 	TokenPort string
 	Secret    string
+
+	// Admins are the IDs of the users made admins by PromoteAdmins.
+	Admins []int
+
+	// DBURL and TokenURL, such as "https://storage:7070", replace the
+	// plain HTTP URLs made from the hosts and ports when they are set.
+	DBURL    string
+	TokenURL string
+
+	// PasswordParams and PasswordPepper configure the Argon2id hashes the
+	// gateway stores instead of plain passwords.
+	PasswordParams password.Params
+	PasswordPepper string
+
+	// PasswordPolicy is checked on sign-up, password change and import, the
+	// zero value accepts any password.
+	PasswordPolicy password.Policy
+
+	// Validator checks imported users, nil only checks their format.
+	Validator *validation.Validator
+
+	// TokenVerifier checks the signature and times of tokens in the
+	// gateway, the token service is then only asked whether they are
+	// revoked. With nil, the token service reads them.
+	TokenVerifier *claims.Verifier
+
+	// Sessions keeps the metadata of the issued tokens, nil keeps it in
+	// memory.
+	Sessions backend.SessionStore
+
+	// Logins keeps the sign-in attempts, nil keeps them in memory. Notifier,
+	// when set, is told about sign-ins from devices new to the user.
+	Logins   backend.LoginHistory
+	Notifier notify.Notifier
 }
 
 type Service interface {
-	SignUp(string, string, string) (string, error)
-	SignIn(string, string) (string, error)
+	SignUp(string, string, string, entity.Client) (string, error)
+	SignIn(string, string, entity.Client) (string, error)
 	LogOut(string) error
+	LogOutAll(string) error
+	ListSessions(string) ([]entity.Session, error)
+	LoginHistory(string) ([]entity.LoginAttempt, error)
+	RevokeSession(string, string) error
 	GetAllUsers() ([]entity.User, error)
+	ListUsers(entity.ListUsersOptions) (entity.UsersPage, error)
+	ExportUsers(context.Context, func(entity.User) error) error
+	ImportUsers(context.Context, []entity.ImportRow, entity.ImportOptions) (entity.ImportReport, error)
 	Profile(string) (entity.User, error)
 	DeleteAccount(string) error
+	ChangePassword(string, string, string) error
+	Authorize(string, ...string) (entity.Claims, error)
+	GetUser(int) (entity.User, error)
+	DisableUser(int) error
+	DeleteUser(int) error
 }
 
 // service ...
 type service struct {
-	client                    petition.HTTPClient
-	dbHost, tokenHost, secret string
+	storage   backend.StorageClient
+	token     backend.TokenClient
+	sessions  backend.SessionStore
+	logins    backend.LoginHistory
+	notifier  notify.Notifier
+	hasher    *password.Hasher
+	policy    password.Policy
+	validator *validation.Validator
+	verifier  *claims.Verifier
+	secret    string
 }
 
 var (
-	ErrResponse      = errors.New("error to response")
-	ErrTokenNotValid = errors.New("token not validate")
-	ErrWebServer     = errors.New("error from web server")
+	ErrResponse        = errors.New("error to response")
+	ErrTokenNotValid   = errors.New("token not validate")
+	ErrWebServer       = errors.New("error from web server")
+	ErrForbidden       = errors.New("forbidden")
+	ErrUserDisabled    = errors.New("user disabled")
+	ErrCredentials     = errors.New("invalid username or password")
+	ErrSessionNotFound = errors.New("session not found")
+	ErrNotSupported    = errors.New("not supported by the backend")
 )
 
-// NewService ...
+// NewService returns the service calling the storage and token services over
+// HTTP with client.
 func NewService(client petition.HTTPClient, is *InfoServices) *service {
+	db, token := is.BaseURLs()
+
+	return NewServiceWithBackends(petition.NewStorageClient(client, db), petition.NewTokenClient(client, token), is)
+}
+
+// NewServiceWithBackends returns the service keeping users in storage and
+// tokens in token. The hosts and ports of is are not used.
+func NewServiceWithBackends(storage backend.StorageClient, token backend.TokenClient, is *InfoServices) *service {
+	validator := is.Validator
+	if validator == nil {
+		validator = validation.NewValidator(nil, nil)
+	}
+
+	sessions := is.Sessions
+	if sessions == nil {
+		sessions = memory.NewSessions(0)
+	}
+
+	logins := is.Logins
+	if logins == nil {
+		logins = memory.NewLogins(0)
+	}
+
 	return &service{
-		client,
-		"http://" + is.DBHost + ":" + is.DBPort, "http://" + is.TokenHost + ":" + is.TokenPort, is.Secret,
+		storage:   storage,
+		token:     token,
+		sessions:  sessions,
+		logins:    logins,
+		notifier:  is.Notifier,
+		validator: validator,
+		verifier:  is.TokenVerifier,
+		hasher:    password.NewHasher(is.PasswordParams, is.PasswordPepper),
+		policy:    is.PasswordPolicy,
+		secret:    is.Secret,
 	}
 }
 
-// SignUp ...
-func (s *service) SignUp(username, password, email string) (token string, err error) {
-	var (
-		errorDBResponse    entity.ErrorResponse
-		idResponse         entity.IDErrorResponse
-		tokenResponse      entity.Token
-		errorTokenResponse entity.ErrorResponse
-	)
-
-	if err = petition.RequestFunc(
-		s.client,
-		entity.UsernamePasswordEmailRequest{
-			Username: username,
-			Password: password,
-			Email:    email,
-		},
-		petition.NewHTTPComponents(
-			s.dbHost+"/user",
-			http.MethodPost,
-		),
-		&errorDBResponse,
-	); err != nil {
-		return "", fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+// BaseURLs returns the URLs of the storage and token services.
+func (is *InfoServices) BaseURLs() (db, token string) {
+	return baseURL(is.DBURL, is.DBHost, is.DBPort), baseURL(is.TokenURL, is.TokenHost, is.TokenPort)
+}
+
+// baseURL is url without its trailing slash, or the plain HTTP URL of host
+// and port when url is "".
+func baseURL(url, host, port string) string {
+	if url == "" {
+		return "http://" + host + ":" + port
 	}
 
-	if errorDBResponse.Err != "" {
-		return "", fmt.Errorf("%w:%s", ErrWebServer, errorDBResponse.Err)
+	return strings.TrimSuffix(url, "/")
+}
+
+// SignUp creates the user, issues it a token and records the session from
+// client.
+func (s *service) SignUp(username, password, email string, client entity.Client) (token string, err error) {
+	ctx := context.Background()
+
+	if err = s.policy.Check(password, username, email); err != nil {
+		return "", err
 	}
 
-	if err = petition.RequestFunc(
-		s.client,
-		entity.UsernameRequest{
-			Username: username,
-		},
-		petition.NewHTTPComponents(
-			s.dbHost+"/id/username",
-			http.MethodGet,
-		),
-		&idResponse,
-	); err != nil {
-		return "", fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	hashed, err := s.hasher.Hash(password)
+	if err != nil {
+		return "", err
 	}
 
-	if idResponse.Err != "" {
-		return "", fmt.Errorf("%w:%s", ErrWebServer, idResponse.Err)
-	}
-
-	if err = petition.RequestFunc(
-		s.client,
-		entity.IDUsernameEmailSecretRequest{
-			ID:       idResponse.ID,
-			Username: username,
-			Email:    email,
-			Secret:   s.secret,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/generate",
-			http.MethodPost,
-		),
-		&tokenResponse,
-	); err != nil {
+	if err = s.storage.CreateUser(ctx, entity.UsernamePasswordEmailRequest{
+		Username: username,
+		Password: hashed,
+		Email:    email,
+	}); err != nil {
 		return "", fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if err = petition.RequestFunc(
-		s.client,
-		entity.Token{
-			Token: tokenResponse.Token,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/token",
-			http.MethodPost,
-		),
-		&errorTokenResponse,
-	); err != nil {
+	id, err := s.storage.GetIDByUsername(ctx, username)
+	if err != nil {
 		return "", fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if errorTokenResponse.Err != "" {
-		return "", fmt.Errorf("%w:%s", ErrWebServer, errorTokenResponse.Err)
+	return s.issueToken(ctx, entity.User{ID: id, Username: username, Email: email}, client)
+}
+
+// SignIn issues a token to the user and records the session from client.
+func (s *service) SignIn(username, password string, client entity.Client) (token string, err error) {
+	ctx := context.Background()
+
+	user, err := s.verifyCredentials(username, password)
+	if err == nil && user.Disabled {
+		err = ErrUserDisabled
 	}
 
-	return tokenResponse.Token, nil
-}
+	// Attempts on unknown usernames have no history to go to.
+	if user.ID != 0 {
+		if recordErr := s.recordLogin(ctx, user, client, err); recordErr != nil {
+			if err != nil {
+				log.Printf("failed to record sign-in of user %d: %s", user.ID, recordErr)
 
-// SignIn ...
-func (s *service) SignIn(username, password string) (token string, err error) {
-	var (
-		userErrorResponse entity.UserErrorResponse
-		tokenResponse     entity.Token
-		errorResponse     entity.ErrorResponse
-	)
-
-	if err = petition.RequestFunc(
-		s.client,
-		entity.UsernamePasswordRequest{
-			Username: username,
-			Password: password,
-		},
-		petition.NewHTTPComponents(
-			s.dbHost+"/user/username_password",
-			http.MethodGet,
-		),
-		&userErrorResponse,
-	); err != nil {
-		return "", fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+				return "", err
+			}
+
+			return "", recordErr
+		}
+	}
+
+	if err != nil {
+		return "", err
 	}
 
-	if userErrorResponse.Err != "" {
-		return "", fmt.Errorf("%w:%s", ErrWebServer, userErrorResponse.Err)
-	}
-
-	if err = petition.RequestFunc(
-		s.client,
-		entity.IDUsernameEmailSecretRequest{
-			ID:       userErrorResponse.User.ID,
-			Username: userErrorResponse.User.Username,
-			Email:    userErrorResponse.User.Email,
-			Secret:   s.secret,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/generate",
-			http.MethodPost,
-		),
-		&tokenResponse,
-	); err != nil {
+	return s.issueToken(ctx, user, client)
+}
+
+// issueToken generates a token for user, stores it and records its session
+// from client.
+func (s *service) issueToken(ctx context.Context, user entity.User, client entity.Client) (token string, err error) {
+	if token, err = s.token.Generate(ctx, entity.Claims{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     s.roleOf(user),
+	}, s.secret); err != nil {
 		return "", fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if err = petition.RequestFunc(
-		s.client,
-		entity.Token{
-			Token: tokenResponse.Token,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/token",
-			http.MethodPost,
-		),
-		&errorResponse,
-	); err != nil {
+	if err = s.token.Store(ctx, token); err != nil {
 		return "", fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if errorResponse.Err != "" {
-		return "", fmt.Errorf("%w:%s", ErrWebServer, errorResponse.Err)
+	if err = s.createSession(ctx, token, user.ID, client); err != nil {
+		return "", fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	return tokenResponse.Token, nil
+	return token, nil
 }
 
 // LogOut ...
 func (s *service) LogOut(token string) (err error) {
-	var (
-		checkErrorResponse entity.CheckErrResponse
-		errorResponse      entity.ErrorResponse
-	)
-
-	if err = petition.RequestFunc(
-		s.client,
-		entity.Token{
-			Token: token,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/check",
-			http.MethodPost,
-		),
-		&checkErrorResponse,
-	); err != nil {
+	ctx := context.Background()
+
+	check, err := s.token.Check(ctx, token)
+	if err != nil {
 		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if checkErrorResponse.Err != "" {
-		return fmt.Errorf("%w:%s", ErrWebServer, checkErrorResponse.Err)
+	if !check {
+		err = ErrTokenNotValid
+
+		return err
 	}
 
-	if !checkErrorResponse.Check {
-		err = ErrTokenNotValid
+	if err = s.token.Revoke(ctx, token); err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	// Tokens issued by another instance or before a restart have no session
+	// in a memory store.
+	if err = s.sessions.DeleteSession(ctx, token); err != nil && !errors.Is(err, backend.ErrResponse) {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	return nil
+}
 
+// LogOutAll revokes every token of the owner of token, this one included.
+// Token services without bulk revocation fail with ErrNotSupported: the
+// sessions the gateway knows do not list every token, and tokens without
+// exp would be left valid for ever.
+func (s *service) LogOutAll(token string) (err error) {
+	claims, err := s.claims(token)
+	if err != nil {
 		return err
 	}
 
-	if err = petition.RequestFunc(
-		s.client,
-		entity.Token{
-			Token: token,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/token",
-			http.MethodDelete,
-		),
-		&errorResponse,
-	); err != nil {
+	ctx := context.Background()
+
+	if err = s.token.RevokeUser(ctx, claims.ID); err != nil {
+		if errors.Is(err, backend.ErrNotSupported) {
+			return fmt.Errorf("%w:%s", ErrNotSupported, err.Error())
+		}
+
 		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if errorResponse.Err != "" {
-		return fmt.Errorf("%w:%s", ErrWebServer, errorResponse.Err)
+	if err = s.sessions.DeleteUserSessions(ctx, claims.ID); err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
 	return nil
@@ -250,162 +300,111 @@ func (s *service) LogOut(token string) (err error) {
 
 // GetAllUsers  ...
 func (s *service) GetAllUsers() (users []entity.User, err error) {
-	var usersErrorResponse entity.UsersErrorResponse
-
-	if err = petition.RequestFuncWithoutBody(
-		s.client,
-		petition.NewHTTPComponents(
-			s.dbHost+"/users",
-			http.MethodGet,
-		),
-		&usersErrorResponse,
-	); err != nil {
+	page, _, err := s.storage.ListUsers(context.Background(), entity.ListUsersOptions{})
+	if err != nil {
 		return nil, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if usersErrorResponse.Err != "" {
-		return nil, fmt.Errorf("%w:%s", ErrWebServer, usersErrorResponse.Err)
-	}
-
-	return usersErrorResponse.Users, nil
+	return withoutPasswords(page.Users), nil
 }
 
 // Profile  ...
 func (s *service) Profile(token string) (user entity.User, err error) {
-	var (
-		checkErrorResponse         entity.CheckErrResponse
-		idUsernameEmailErrResponse entity.IDUsernameEmailErrResponse
-		userErrorResponse          entity.UserErrorResponse
-	)
-
-	if err = petition.RequestFunc(
-		s.client,
-		entity.Token{
-			Token: token,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/check",
-			http.MethodPost,
-		),
-		&checkErrorResponse,
-	); err != nil {
-		return entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
-	}
-
-	if checkErrorResponse.Err != "" {
-		return entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, checkErrorResponse.Err)
-	}
-
-	if !checkErrorResponse.Check {
-		err = ErrTokenNotValid
-
+	claims, user, err := s.authenticate(token)
+	if err != nil {
 		return entity.User{}, err
 	}
 
-	if err = petition.RequestFunc(
-		s.client,
-		entity.TokenSecretRequest{
-			Token:  token,
-			Secret: s.secret,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/extract",
-			http.MethodPost,
-		),
-		&idUsernameEmailErrResponse,
-	); err != nil {
-		return entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	user.Role = claims.Role
+	user.Password = ""
+
+	return user, nil
+}
+
+// DeleteAccount  ...
+func (s *service) DeleteAccount(token string) (err error) {
+	ctx := context.Background()
+
+	claims, err := s.claims(token)
+	if err != nil {
+		return err
 	}
 
-	if idUsernameEmailErrResponse.Err != "" {
-		return entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, idUsernameEmailErrResponse.Err)
+	// The tokens go first, an account left behind by a failed delete is
+	// safer than tokens left to a deleted one.
+	if err = s.revokeUserTokens(ctx, claims.ID); err != nil {
+		return err
 	}
 
-	if err = petition.RequestFunc(
-		s.client,
-		entity.IDRequest{
-			ID: idUsernameEmailErrResponse.ID,
-		},
-		petition.NewHTTPComponents(
-			s.dbHost+"/user/id",
-			http.MethodGet,
-		),
-		&userErrorResponse,
-	); err != nil {
-		return entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	if err = s.storage.DeleteUser(ctx, claims.ID); err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if userErrorResponse.Err != "" {
-		return entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, userErrorResponse.Err)
+	return nil
+}
+
+// withoutPasswords clears the stored passwords of users, hashes are never
+// answered.
+func withoutPasswords(users []entity.User) []entity.User {
+	for i := range users {
+		users[i].Password = ""
 	}
 
-	return userErrorResponse.User, nil
+	return users
 }
 
-// DeleteAccount  ...
-func (s *service) DeleteAccount(token string) (err error) {
-	var (
-		checkErrorResponse         entity.CheckErrResponse
-		idUsernameEmailErrResponse entity.IDUsernameEmailErrResponse
-		errorResponse              entity.ErrorResponse
-	)
-
-	if err = petition.RequestFunc(
-		s.client,
-		entity.Token{
-			Token: token,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/check",
-			http.MethodPost,
-		),
-		&checkErrorResponse,
-	); err != nil {
-		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+// claims validates the token and returns the identity stored in it. The
+// token is read in the gateway when it has a verifier, the token service
+// only tells whether it is revoked.
+func (s *service) claims(token string) (claims entity.Claims, err error) {
+	claims, _, err = s.authenticate(token)
+
+	return claims, err
+}
+
+// authenticate is claims returning the stored user too, which is read to
+// refuse the tokens of disabled users.
+func (s *service) authenticate(token string) (claims entity.Claims, user entity.User, err error) {
+	ctx := context.Background()
+
+	if s.verifier != nil {
+		if claims, err = s.verifier.Verify(token); err != nil {
+			return entity.Claims{}, entity.User{}, fmt.Errorf("%w: %s", ErrTokenNotValid, err.Error())
+		}
 	}
 
-	if checkErrorResponse.Err != "" {
-		return fmt.Errorf("%w:%s", ErrWebServer, checkErrorResponse.Err)
+	check, err := s.token.Check(ctx, token)
+	if err != nil {
+		return entity.Claims{}, entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if !checkErrorResponse.Check {
-		err = ErrTokenNotValid
+	if !check {
+		return entity.Claims{}, entity.User{}, ErrTokenNotValid
+	}
 
-		return err
+	if err = s.sessions.TouchSession(ctx, token, time.Now()); err != nil && !errors.Is(err, backend.ErrResponse) {
+		return entity.Claims{}, entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if err = petition.RequestFunc(
-		s.client,
-		entity.TokenSecretRequest{
-			Token:  token,
-			Secret: s.secret,
-		},
-		petition.NewHTTPComponents(
-			s.tokenHost+"/extract",
-			http.MethodPost,
-		),
-		&idUsernameEmailErrResponse,
-	); err != nil {
-		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	if s.verifier == nil {
+		if claims, err = s.token.Extract(ctx, token, s.secret); err != nil {
+			return entity.Claims{}, entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+		}
 	}
 
-	if idUsernameEmailErrResponse.Err != "" {
-		return fmt.Errorf("%w:%s", ErrWebServer, idUsernameEmailErrResponse.Err)
+	// The disabled flag is read from the storage, tokens of disabled users
+	// stay valid in the token service.
+	if user, err = s.storage.GetUserByID(ctx, claims.ID); err != nil {
+		return entity.Claims{}, entity.User{}, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
 	}
 
-	if err = petition.RequestFunc(
-		s.client,
-		entity.IDRequest{
-			ID: idUsernameEmailErrResponse.ID,
-		},
-		petition.NewHTTPComponents(
-			s.dbHost+"/user",
-			http.MethodDelete,
-		),
-		&errorResponse,
-	); err != nil {
-		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	if user.Disabled {
+		return entity.Claims{}, entity.User{}, ErrUserDisabled
 	}
 
-	return nil
+	// The role too, the one in the token may be outdated or not kept by the
+	// token service.
+	claims.Role = s.roleOf(user)
+
+	return claims, user, nil
 }
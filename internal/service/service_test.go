@@ -2,14 +2,19 @@ package service_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"app/internal/backend"
+	"app/internal/claims"
 	"app/internal/entity"
 	"app/internal/entity/mock"
+	"app/internal/revocation"
 	"app/internal/service"
 
 	httpMock "app/internal/service/mock"
@@ -136,8 +141,8 @@ func getProfileTestEntity() []profileTestStruct {
 			outUser: entity.User{
 				ID:       mock.IDTest,
 				Username: mock.UsernameTest,
-				Password: mock.PasswordTest,
 				Email:    mock.EmailTest,
+				Role:     entity.RoleUser,
 			},
 			outCheck: true,
 			isError:  false,
@@ -266,7 +271,7 @@ func TestSignUp(t *testing.T) {
 				&infoServiceTest,
 			)
 
-			resultToken, resultErr = svc.SignUp(tt.inUsername, tt.inPassword, tt.inEmail)
+			resultToken, resultErr = svc.SignUp(tt.inUsername, tt.inPassword, tt.inEmail, entity.Client{})
 
 			if !tt.isError {
 				assert.Nil(t, resultErr)
@@ -292,6 +297,7 @@ func TestSignIn(t *testing.T) {
 	for _, tt := range []struct {
 		name                   string
 		inUsername, inPassword string
+		outErr                 string
 		url                    string
 		method                 string
 		isError                bool
@@ -320,6 +326,24 @@ func TestSignIn(t *testing.T) {
 			isError:              true,
 			isErrorInsideRequest: true,
 			url:                  "http://db:8080/user/username_password",
+			outErr:               service.ErrCredentials.Error(),
+			method:               http.MethodGet,
+		},
+		{
+			name:       "ErrorGetID",
+			inUsername: mock.UsernameTest,
+			inPassword: mock.PasswordTest,
+			isError:    true,
+			url:        "http://db:8080/id/username",
+			method:     http.MethodGet,
+		},
+		{
+			name:                 "ErrorInsideGetUserByID",
+			inUsername:           mock.UsernameTest,
+			inPassword:           mock.PasswordTest,
+			isError:              true,
+			isErrorInsideRequest: true,
+			url:                  "http://db:8080/user/id",
 			method:               http.MethodGet,
 		},
 		{
@@ -359,11 +383,15 @@ func TestSignIn(t *testing.T) {
 
 			if tt.isError {
 				errorResponse = mock.ErrWebServer.Error()
+				if tt.outErr != "" {
+					errorResponse = tt.outErr
+				}
 			} else {
 				tokenResponse = mock.TokenTest
 			}
 
 			responseJSON := `{
+					"id":1,
 					"token":"token",
 					"user":{
 						"id":       1,
@@ -392,7 +420,7 @@ func TestSignIn(t *testing.T) {
 				&infoServiceTest,
 			)
 
-			resultToken, resultErr = svc.SignIn(tt.inUsername, tt.inPassword)
+			resultToken, resultErr = svc.SignIn(tt.inUsername, tt.inPassword, entity.Client{})
 
 			if !tt.isError {
 				assert.Nil(t, resultErr)
@@ -542,7 +570,6 @@ func TestGetAllUsers(t *testing.T) {
 				{
 					ID:       mock.IDTest,
 					Username: mock.UsernameTest,
-					Password: mock.PasswordTest,
 					Email:    mock.EmailTest,
 				},
 			},
@@ -766,13 +793,39 @@ func TestDeleteAccount(t *testing.T) {
 			method:               http.MethodPost,
 		},
 		{
-			name:     "ErrorDeleteToken",
+			name:     "ErrorRevokeTokens",
+			inToken:  mock.TokenTest,
+			outCheck: true,
+			isError:  true,
+			url:      "http://token:8080/tokens",
+			method:   http.MethodDelete,
+		},
+		{
+			name:                 "ErrorInsideRevokeTokens",
+			inToken:              mock.TokenTest,
+			outCheck:             true,
+			isError:              true,
+			isErrorInsideRequest: true,
+			url:                  "http://token:8080/tokens",
+			method:               http.MethodDelete,
+		},
+		{
+			name:     "ErrorDeleteUser",
 			inToken:  mock.TokenTest,
 			outCheck: true,
 			isError:  true,
 			url:      "http://db:8080/user",
 			method:   http.MethodDelete,
 		},
+		{
+			name:                 "ErrorInsideDeleteUser",
+			inToken:              mock.TokenTest,
+			outCheck:             true,
+			isError:              true,
+			isErrorInsideRequest: true,
+			url:                  "http://db:8080/user",
+			method:               http.MethodDelete,
+		},
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
@@ -874,3 +927,209 @@ func getIsErrorMock(
 		}, nil
 	}
 }
+
+func TestNewServiceBackendURLs(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		in     service.InfoServices
+		outURL string
+	}{
+		{
+			name:   "HostAndPort",
+			in:     service.InfoServices{TokenHost: mock.TokenHostTest, TokenPort: mock.PortTest},
+			outURL: "http://token:8080/check",
+		},
+		{
+			name: "URL",
+			in: service.InfoServices{
+				TokenHost: mock.TokenHostTest,
+				TokenPort: mock.PortTest,
+				TokenURL:  "https://token.internal:9443/",
+			},
+			outURL: "https://token.internal:9443/check",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var url string
+
+			svc := service.NewService(httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				url = req.URL.String()
+
+				return &http.Response{Body: io.NopCloser(strings.NewReader(`{"check":false}`))}, nil
+			}), &tt.in)
+
+			assert.ErrorIs(t, svc.LogOut(mock.TokenTest), service.ErrTokenNotValid)
+			assert.Equal(t, tt.outURL, url)
+		})
+	}
+}
+
+// storageTest is a backend.StorageClient keeping one user.
+type storageTest struct {
+	backend.StorageClient
+	user entity.User
+}
+
+func (s *storageTest) GetUserByID(_ context.Context, id int) (entity.User, error) {
+	if id != s.user.ID {
+		return entity.User{}, &backend.ResponseError{Message: "not found"}
+	}
+
+	return s.user, nil
+}
+
+func (s *storageTest) DeleteUser(_ context.Context, _ int) error {
+	return nil
+}
+
+// tokenTest is a backend.TokenClient knowing one token.
+type tokenTest struct {
+	backend.TokenClient
+	claims  entity.Claims
+	revoked bool
+}
+
+func (t *tokenTest) Check(_ context.Context, token string) (bool, error) {
+	return token == mock.TokenTest && !t.revoked, nil
+}
+
+func (t *tokenTest) Extract(_ context.Context, _, _ string) (entity.Claims, error) {
+	return t.claims, nil
+}
+
+func (t *tokenTest) Revoke(_ context.Context, _ string) error {
+	t.revoked = true
+
+	return nil
+}
+
+func (t *tokenTest) RevokeUser(_ context.Context, id int) error {
+	t.revoked = t.revoked || id == t.claims.ID
+
+	return nil
+}
+
+func TestNewServiceWithBackends(t *testing.T) {
+	t.Parallel()
+
+	user := entity.User{ID: mock.IDTest, Username: mock.UsernameTest, Email: mock.EmailTest}
+	token := &tokenTest{claims: entity.Claims{ID: mock.IDTest, Username: mock.UsernameTest, Email: mock.EmailTest}}
+
+	svc := service.NewServiceWithBackends(&storageTest{user: user}, token, getAdminInfoServices())
+
+	profile, err := svc.Profile(mock.TokenTest)
+	assert.Nil(t, err)
+	assert.Equal(t, mock.UsernameTest, profile.Username)
+	assert.Equal(t, entity.RoleUser, profile.Role)
+
+	_, err = svc.GetUser(2)
+	assert.ErrorIs(t, err, service.ErrWebServer)
+	assert.EqualError(t, err, "error from web server:not found")
+
+	assert.Nil(t, svc.LogOut(mock.TokenTest))
+	assert.ErrorIs(t, svc.LogOut(mock.TokenTest), service.ErrTokenNotValid)
+}
+
+func TestDeleteAccountRevokesTokens(t *testing.T) {
+	t.Parallel()
+
+	user := entity.User{ID: mock.IDTest, Username: mock.UsernameTest, Email: mock.EmailTest}
+	cache := revocation.NewCache(&tokenTest{claims: entity.Claims{ID: mock.IDTest}}, revocation.Config{}, nil)
+
+	svc := service.NewServiceWithBackends(&storageTest{user: user}, cache, getAdminInfoServices())
+
+	_, err := svc.Profile(mock.TokenTest)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cache.Stats().Entries)
+
+	assert.Nil(t, svc.DeleteAccount(mock.TokenTest))
+	assert.Equal(t, 0, cache.Stats().Entries)
+
+	_, err = svc.Profile(mock.TokenTest)
+	assert.ErrorIs(t, err, service.ErrTokenNotValid)
+}
+
+func TestProfileLocalVerification(t *testing.T) {
+	t.Parallel()
+
+	signed, err := claims.Sign(
+		[]byte(mock.SecretTest),
+		entity.Claims{ID: mock.IDTest, Username: mock.UsernameTest, Email: mock.EmailTest},
+		"id",
+		time.Now(),
+	)
+	assert.Nil(t, err)
+
+	forged, err := claims.Sign([]byte("other"), entity.Claims{ID: 2, Username: adminUsernameTest}, "id", time.Now())
+	assert.Nil(t, err)
+
+	verifier, err := claims.NewVerifier([]byte(mock.SecretTest), claims.VerifierConfig{}, nil)
+	assert.Nil(t, err)
+
+	for _, tt := range []struct {
+		name    string
+		inToken string
+		inCheck string
+		outURLs []string
+		outErr  error
+	}{
+		{
+			name:    mock.NameNoError,
+			inToken: signed,
+			inCheck: `{"check":true}`,
+			outURLs: []string{"http://token:8080/check", "http://db:8080/user/id"},
+		},
+		{
+			name:    "ErrorRevoked",
+			inToken: signed,
+			inCheck: `{"check":false}`,
+			outURLs: []string{"http://token:8080/check"},
+			outErr:  service.ErrTokenNotValid,
+		},
+		{
+			name:    "ErrorSignature",
+			inToken: forged,
+			inCheck: `{"check":true}`,
+			outErr:  service.ErrTokenNotValid,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var urls []string
+
+			infoServices := getAdminInfoServices()
+			infoServices.TokenVerifier = verifier
+
+			svc := service.NewService(httpMock.NewMockClient(func(req *http.Request) (*http.Response, error) {
+				urls = append(urls, req.URL.String())
+
+				body := `{"user":{"id":1,"username":"username","email":"email@email.com"}}`
+				if req.URL.Path == "/check" {
+					body = tt.inCheck
+				}
+
+				return &http.Response{Body: io.NopCloser(strings.NewReader(body))}, nil
+			}), infoServices)
+
+			user, err := svc.Profile(tt.inToken)
+			assert.Equal(t, tt.outURLs, urls)
+
+			if tt.outErr != nil {
+				assert.ErrorIs(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, mock.UsernameTest, user.Username)
+			assert.Equal(t, entity.RoleUser, user.Role)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"app/internal/backend"
+	"app/internal/entity"
+)
+
+const sessionIDBytes int = 16
+
+// ListSessions returns the sessions of the owner of token, the last used
+// first, marking the one of token as current.
+func (s *service) ListSessions(token string) (sessions []entity.Session, err error) {
+	claims, err := s.claims(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if sessions, err = s.sessions.ListSessions(context.Background(), claims.ID); err != nil {
+		return nil, fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].Token == token
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes the token of the session id, which must belong to
+// the owner of token.
+func (s *service) RevokeSession(token, id string) (err error) {
+	ctx := context.Background()
+
+	claims, err := s.claims(token)
+	if err != nil {
+		return err
+	}
+
+	session, err := s.sessions.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, backend.ErrResponse) {
+			return ErrSessionNotFound
+		}
+
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	// The sessions of others are not told apart from missing ones.
+	if session.UserID != claims.ID {
+		return ErrSessionNotFound
+	}
+
+	if err = s.token.Revoke(ctx, session.Token); err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	if err = s.sessions.DeleteSession(ctx, session.Token); err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	return nil
+}
+
+// revokeUserTokens revokes the tokens of the user id before it is deleted and
+// forgets its sessions. Token services without bulk revocation get the
+// tokens of the sessions revoked one by one instead; the tokens the sessions
+// miss are refused anyway once the user is gone, see authenticate.
+func (s *service) revokeUserTokens(ctx context.Context, userID int) (err error) {
+	err = s.token.RevokeUser(ctx, userID)
+	if errors.Is(err, backend.ErrNotSupported) {
+		err = s.revokeSessionTokens(ctx, userID)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	if err = s.sessions.DeleteUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("%w:%s", ErrWebServer, err.Error())
+	}
+
+	return nil
+}
+
+// revokeSessionTokens revokes the token of each session of the user id. The
+// ones the token service no longer knows are already revoked.
+func (s *service) revokeSessionTokens(ctx context.Context, userID int) (err error) {
+	sessions, err := s.sessions.ListSessions(ctx, userID)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	for _, session := range sessions {
+		if err = s.token.Revoke(ctx, session.Token); err != nil && !errors.Is(err, backend.ErrResponse) {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	return nil
+}
+
+// createSession records the session of a token issued to the user id.
+func (s *service) createSession(ctx context.Context, token string, userID int, client entity.Client) (err error) {
+	id := make([]byte, sessionIDBytes)
+	if _, err = rand.Read(id); err != nil {
+		return fmt.Errorf("failed to generate session ID: %w", err)
+	}
+
+	now := time.Now()
+
+	return s.sessions.CreateSession(ctx, entity.Session{ //nolint:wrapcheck
+		ID:        hex.EncodeToString(id),
+		Token:     token,
+		UserID:    userID,
+		CreatedAt: now,
+		LastSeen:  now,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Device:    deviceLabel(client.UserAgent),
+	})
+}
+
+// deviceLabel names the browser and system of userAgent, such as "Firefox on
+// Linux", or its first product for other clients.
+func deviceLabel(userAgent string) (label string) {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := firstMatch(userAgent, [][2]string{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	})
+
+	system := firstMatch(userAgent, [][2]string{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	})
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	}
+
+	product, _, _ := strings.Cut(userAgent, " ")
+	product, _, _ = strings.Cut(product, "/")
+
+	return product
+}
+
+// firstMatch returns the name of the first pair whose marker is in s, or "".
+func firstMatch(s string, pairs [][2]string) (name string) {
+	for _, pair := range pairs {
+		if strings.Contains(s, pair[0]) {
+			return pair[1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"app/internal/backend"
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/memory"
+	"app/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	firefoxTest = "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0"
+	iPhoneTest  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 " +
+		"(KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	svc := service.NewServiceWithBackends(memory.NewStorage(), memory.NewTokens(nil, 0), &service.InfoServices{
+		Secret: mock.SecretTest,
+	})
+
+	_, err := svc.SignUp(mock.UsernameTest, mock.PasswordTest, mock.EmailTest, entity.Client{
+		IP:        "10.0.0.3",
+		UserAgent: "curl/8.0",
+	})
+	assert.Nil(t, err)
+
+	other, err := svc.SignUp("other", mock.PasswordTest, "other@email.com", entity.Client{})
+	assert.Nil(t, err)
+
+	laptop, err := svc.SignIn(mock.UsernameTest, mock.PasswordTest, entity.Client{IP: "10.0.0.1", UserAgent: firefoxTest})
+	assert.Nil(t, err)
+
+	phone, err := svc.SignIn(mock.UsernameTest, mock.PasswordTest, entity.Client{IP: "10.0.0.2", UserAgent: iPhoneTest})
+	assert.Nil(t, err)
+
+	sessions, err := svc.ListSessions(phone)
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 3)
+
+	devices := make(map[string]entity.Session, len(sessions))
+	for _, session := range sessions {
+		devices[session.Device] = session
+	}
+
+	assert.Equal(t, "10.0.0.1", devices["Firefox on Linux"].IP)
+	assert.False(t, devices["Firefox on Linux"].Current)
+	assert.Equal(t, "10.0.0.2", devices["Safari on iOS"].IP)
+	assert.True(t, devices["Safari on iOS"].Current)
+	assert.Equal(t, "10.0.0.3", devices["curl"].IP)
+
+	laptopID := devices["Firefox on Linux"].ID
+
+	assert.ErrorIs(t, svc.RevokeSession(other, laptopID), service.ErrSessionNotFound)
+	assert.ErrorIs(t, svc.RevokeSession(phone, "missing"), service.ErrSessionNotFound)
+	assert.Nil(t, svc.RevokeSession(phone, laptopID))
+
+	_, err = svc.Profile(laptop)
+	assert.ErrorIs(t, err, service.ErrTokenNotValid)
+
+	sessions, err = svc.ListSessions(phone)
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 2)
+
+	assert.Nil(t, svc.LogOut(phone))
+
+	sessions, err = svc.ListSessions(other)
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 1)
+
+	_, err = svc.ListSessions(phone)
+	assert.ErrorIs(t, err, service.ErrTokenNotValid)
+}
+
+// tokensWithoutBulkTest is a token service without bulk revocation.
+type tokensWithoutBulkTest struct {
+	*memory.Tokens
+}
+
+func (t tokensWithoutBulkTest) RevokeUser(_ context.Context, _ int) error {
+	return backend.ErrNotSupported
+}
+
+func TestWithoutBulkRevoke(t *testing.T) {
+	t.Parallel()
+
+	svc := service.NewServiceWithBackends(memory.NewStorage(), tokensWithoutBulkTest{memory.NewTokens(nil, 0)},
+		&service.InfoServices{Secret: mock.SecretTest})
+
+	_, err := svc.SignUp(mock.UsernameTest, mock.PasswordTest, mock.EmailTest, entity.Client{})
+	assert.Nil(t, err)
+
+	other, err := svc.SignUp("other", mock.PasswordTest, "other@email.com", entity.Client{})
+	assert.Nil(t, err)
+
+	laptop, err := svc.SignIn(mock.UsernameTest, mock.PasswordTest, entity.Client{UserAgent: firefoxTest})
+	assert.Nil(t, err)
+
+	phone, err := svc.SignIn(mock.UsernameTest, mock.PasswordTest, entity.Client{UserAgent: iPhoneTest})
+	assert.Nil(t, err)
+
+	// Logging out everywhere would only reach the known sessions.
+	assert.ErrorIs(t, svc.LogOutAll(phone), service.ErrNotSupported)
+
+	_, err = svc.Profile(laptop)
+	assert.Nil(t, err)
+
+	// Deleting the account revokes them one by one.
+	assert.Nil(t, svc.DeleteAccount(phone))
+
+	_, err = svc.Profile(laptop)
+	assert.ErrorIs(t, err, service.ErrTokenNotValid)
+
+	_, err = svc.Profile(phone)
+	assert.ErrorIs(t, err, service.ErrTokenNotValid)
+
+	_, err = svc.Profile(other)
+	assert.Nil(t, err)
+}
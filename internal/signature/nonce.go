@@ -0,0 +1,49 @@
+package signature
+
+import (
+	"sync"
+	"time"
+)
+
+// nonceCache remembers the nonces of the last window in two generations:
+// a nonce is kept at least one window and at most two, and the old
+// generation is dropped as a whole instead of expiring nonces one by one.
+type nonceCache struct {
+	rotated           time.Time
+	current, previous map[string]struct{}
+	window            time.Duration
+	mutex             sync.Mutex
+}
+
+func newNonceCache(window time.Duration) *nonceCache {
+	return &nonceCache{
+		window:   window,
+		current:  make(map[string]struct{}),
+		previous: make(map[string]struct{}),
+	}
+}
+
+// add reports whether nonce was not seen yet, and remembers it.
+func (c *nonceCache) add(nonce string, now time.Time) (check bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	switch elapsed := now.Sub(c.rotated); {
+	case elapsed >= 2*c.window:
+		c.previous, c.current, c.rotated = make(map[string]struct{}), make(map[string]struct{}), now
+	case elapsed >= c.window:
+		c.previous, c.current, c.rotated = c.current, make(map[string]struct{}), now
+	}
+
+	if _, ok := c.current[nonce]; ok {
+		return false
+	}
+
+	if _, ok := c.previous[nonce]; ok {
+		return false
+	}
+
+	c.current[nonce] = struct{}{}
+
+	return true
+}
@@ -0,0 +1,215 @@
+// Package signature signs HTTP requests with a shared key and verifies them,
+// so a backend only answers the gateway and not any process that can reach
+// it. The signature covers the method, the path and query, a digest of the
+// body, a timestamp and a nonce.
+package signature
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of a signed request.
+const (
+	HeaderSignature = "X-Signature"
+	HeaderTimestamp = "X-Signature-Timestamp"
+	HeaderNonce     = "X-Signature-Nonce"
+	HeaderDigest    = "X-Content-SHA256"
+)
+
+// DefaultSkew is the clock difference tolerated between signer and
+// verifier when none is given.
+const DefaultSkew = 30 * time.Second
+
+// DefaultMaxBodyBytes bounds the bodies the verifier reads.
+const DefaultMaxBodyBytes int64 = 10 << 20
+
+const nonceBytes int = 16
+
+var (
+	ErrMissing   = errors.New("missing signature")
+	ErrInvalid   = errors.New("invalid signature")
+	ErrExpired   = errors.New("signature timestamp out of range")
+	ErrReplayed  = errors.New("signature nonce already used")
+	ErrBody      = errors.New("failed to read body")
+	ErrEmptyKey  = errors.New("empty signing key")
+	errTooLarge  = errors.New("body too large")
+	errBadDigest = errors.New("body digest mismatch")
+)
+
+// Signer signs requests with key.
+type Signer struct {
+	now func() time.Time
+	key []byte
+}
+
+// NewSigner returns a Signer reading the time from now, nil for time.Now.
+func NewSigner(key []byte, now func() time.Time) (*Signer, error) {
+	if len(key) == 0 {
+		return nil, ErrEmptyKey
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+
+	return &Signer{key: key, now: now}, nil
+}
+
+// Sign sets the signature headers of req, whose body is body.
+func (s *Signer) Sign(req *http.Request, body []byte) (err error) {
+	nonce := make([]byte, nonceBytes)
+	if _, err = rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	digest := sha256.Sum256(body)
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+
+	req.Header.Set(HeaderDigest, base64.StdEncoding.EncodeToString(digest[:]))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, hex.EncodeToString(nonce))
+	req.Header.Set(HeaderSignature, sign(s.key, req))
+
+	return nil
+}
+
+// Verifier checks the signatures made by a Signer with the same key.
+type Verifier struct {
+	nonces       *nonceCache
+	now          func() time.Time
+	key          []byte
+	skew         time.Duration
+	maxBodyBytes int64
+}
+
+// NewVerifier returns a Verifier accepting timestamps up to skew away from
+// now, DefaultSkew when 0, and reading the time from now, nil for time.Now.
+func NewVerifier(key []byte, skew time.Duration, now func() time.Time) (*Verifier, error) {
+	if len(key) == 0 {
+		return nil, ErrEmptyKey
+	}
+
+	if skew <= 0 {
+		skew = DefaultSkew
+	}
+
+	if now == nil {
+		now = time.Now
+	}
+
+	return &Verifier{
+		key:          key,
+		skew:         skew,
+		now:          now,
+		nonces:       newNonceCache(2 * skew),
+		maxBodyBytes: DefaultMaxBodyBytes,
+	}, nil
+}
+
+// Verify checks the signature of req and leaves its body readable again. A
+// nonce is only accepted once within the skew window.
+func (v *Verifier) Verify(req *http.Request) (err error) {
+	signature := req.Header.Get(HeaderSignature)
+	if signature == "" || req.Header.Get(HeaderTimestamp) == "" || req.Header.Get(HeaderNonce) == "" {
+		return ErrMissing
+	}
+
+	body, err := v.readBody(req)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(body)
+	if req.Header.Get(HeaderDigest) != base64.StdEncoding.EncodeToString(digest[:]) {
+		return fmt.Errorf("%w: %s", ErrInvalid, errBadDigest.Error())
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(v.key, req))) {
+		return ErrInvalid
+	}
+
+	seconds, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalid, err.Error())
+	}
+
+	now := v.now()
+	if timestamp := time.Unix(seconds, 0); timestamp.Before(now.Add(-v.skew)) || timestamp.After(now.Add(v.skew)) {
+		return ErrExpired
+	}
+
+	if !v.nonces.add(req.Header.Get(HeaderNonce), now) {
+		return ErrReplayed
+	}
+
+	return nil
+}
+
+// Middleware answers requests that fail Verify with 401 and an
+// {"err": ...} body.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Verify(r); err != nil {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(struct {
+				Err string `json:"err"`
+			}{Err: err.Error()})
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (v *Verifier) readBody(req *http.Request) (body []byte, err error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err = io.ReadAll(io.LimitReader(req.Body, v.maxBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBody, err.Error())
+	}
+
+	if int64(len(body)) > v.maxBodyBytes {
+		return nil, fmt.Errorf("%w: %s", ErrBody, errTooLarge.Error())
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// sign is the HMAC-SHA256 of the signed parts of req.
+func sign(key []byte, req *http.Request) string {
+	target := req.URL.EscapedPath()
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join([]string{
+		req.Method,
+		target,
+		req.Header.Get(HeaderDigest),
+		req.Header.Get(HeaderTimestamp),
+		req.Header.Get(HeaderNonce),
+	}, "\n")))
+
+	return "v1=" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,234 @@
+package signature_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"app/internal/entity/mock"
+	"app/internal/signature"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clockTest is a clock the tests move by hand.
+type clockTest struct {
+	now time.Time
+}
+
+func (c *clockTest) Now() time.Time {
+	return c.now
+}
+
+func newSignedRequestTest(t *testing.T, signer *signature.Signer, target, body string) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	assert.Nil(t, signer.Sign(req, []byte(body)))
+
+	return req
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	signedAt := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	signer, err := signature.NewSigner([]byte(mock.SecretTest), func() time.Time { return signedAt })
+	assert.Nil(t, err)
+
+	for _, tt := range []struct {
+		tamper func(*http.Request)
+		name   string
+		inKey  string
+		inSkew time.Duration
+		outErr error
+	}{
+		{
+			name: mock.NameNoError,
+		},
+		{
+			name:   "NoErrorWithinSkew",
+			inSkew: 30 * time.Second,
+			tamper: func(*http.Request) {},
+		},
+		{
+			name:   "ErrorMissing",
+			tamper: func(r *http.Request) { r.Header.Del(signature.HeaderSignature) },
+			outErr: signature.ErrMissing,
+		},
+		{
+			name:   "ErrorKey",
+			inKey:  "other",
+			outErr: signature.ErrInvalid,
+		},
+		{
+			name:   "ErrorPath",
+			tamper: func(r *http.Request) { r.URL.Path = "/user/2" },
+			outErr: signature.ErrInvalid,
+		},
+		{
+			name:   "ErrorQuery",
+			tamper: func(r *http.Request) { r.URL.RawQuery = "admin=true" },
+			outErr: signature.ErrInvalid,
+		},
+		{
+			name:   "ErrorMethod",
+			tamper: func(r *http.Request) { r.Method = http.MethodDelete },
+			outErr: signature.ErrInvalid,
+		},
+		{
+			name:   "ErrorBody",
+			tamper: func(r *http.Request) { r.Body = io.NopCloser(strings.NewReader(`{"id":2}`)) },
+			outErr: signature.ErrInvalid,
+		},
+		{
+			name:   "ErrorTimestamp",
+			tamper: func(r *http.Request) { r.Header.Set(signature.HeaderTimestamp, "1767272400") },
+			outErr: signature.ErrInvalid,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			key := mock.SecretTest
+			if tt.inKey != "" {
+				key = tt.inKey
+			}
+
+			clock := &clockTest{now: signedAt.Add(tt.inSkew)}
+			verifier, err := signature.NewVerifier([]byte(key), tt.inSkew, clock.Now)
+			assert.Nil(t, err)
+
+			req := newSignedRequestTest(t, signer, "/user/1?full=true", `{"id":1}`)
+			if tt.tamper != nil {
+				tt.tamper(req)
+			}
+
+			err = verifier.Verify(req)
+			if tt.outErr != nil {
+				assert.ErrorIs(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+
+			body, err := io.ReadAll(req.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, `{"id":1}`, string(body))
+		})
+	}
+}
+
+func TestVerifyClock(t *testing.T) {
+	t.Parallel()
+
+	signedAt := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	signer, err := signature.NewSigner([]byte(mock.SecretTest), func() time.Time { return signedAt })
+	assert.Nil(t, err)
+
+	for _, tt := range []struct {
+		name   string
+		inNow  time.Time
+		outErr error
+	}{
+		{
+			name:  "Skewed",
+			inNow: signedAt.Add(-10 * time.Second),
+		},
+		{
+			name:   "ErrorFuture",
+			inNow:  signedAt.Add(-11 * time.Second),
+			outErr: signature.ErrExpired,
+		},
+		{
+			name:   "ErrorExpired",
+			inNow:  signedAt.Add(11 * time.Second),
+			outErr: signature.ErrExpired,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			verifier, err := signature.NewVerifier([]byte(mock.SecretTest), 10*time.Second, (&clockTest{now: tt.inNow}).Now)
+			assert.Nil(t, err)
+
+			err = verifier.Verify(newSignedRequestTest(t, signer, "/user", ""))
+			if tt.outErr != nil {
+				assert.ErrorIs(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestVerifyReplay(t *testing.T) {
+	t.Parallel()
+
+	clock := &clockTest{now: time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)}
+
+	signer, err := signature.NewSigner([]byte(mock.SecretTest), clock.Now)
+	assert.Nil(t, err)
+
+	verifier, err := signature.NewVerifier([]byte(mock.SecretTest), 10*time.Second, clock.Now)
+	assert.Nil(t, err)
+
+	req := newSignedRequestTest(t, signer, "/user", `{"username":"username"}`)
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"username":"username"}`))
+
+	assert.Nil(t, verifier.Verify(req))
+	assert.ErrorIs(t, verifier.Verify(replay), signature.ErrReplayed)
+
+	// The nonce is still remembered at the end of the skew window of its
+	// timestamp, after which the timestamp alone rejects the request.
+	for _, elapsed := range []time.Duration{5 * time.Second, 5 * time.Second} {
+		clock.now = clock.now.Add(elapsed)
+		replay.Body = io.NopCloser(strings.NewReader(`{"username":"username"}`))
+
+		assert.ErrorIs(t, verifier.Verify(replay), signature.ErrReplayed)
+	}
+
+	// Fresh requests keep working while nonces are rotated out.
+	for i := 0; i < 5; i++ {
+		clock.now = clock.now.Add(15 * time.Second)
+
+		assert.Nil(t, verifier.Verify(newSignedRequestTest(t, signer, "/user", "")))
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	signer, err := signature.NewSigner([]byte(mock.SecretTest), nil)
+	assert.Nil(t, err)
+
+	verifier, err := signature.NewVerifier([]byte(mock.SecretTest), 0, nil)
+	assert.Nil(t, err)
+
+	handler := verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newSignedRequestTest(t, signer, "/user", `{"id":1}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"id":1}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(`{"id":1}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"err":"missing signature"}`, w.Body.String())
+
+	_, err = signature.NewVerifier(nil, 0, nil)
+	assert.ErrorIs(t, err, signature.ErrEmptyKey)
+}
@@ -0,0 +1,30 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"app/internal/backend"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// insertError reports the unique constraint err failed, if any, as the
+// storage service does. The driver only names the column in the message.
+func insertError(err error) error {
+	var sqliteErr sqlite3.Error
+
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		switch {
+		case strings.HasSuffix(sqliteErr.Error(), "users.username"):
+			return &backend.ResponseError{Message: errUsernameTaken.Error()}
+		case strings.HasSuffix(sqliteErr.Error(), "users.email"):
+			return &backend.ResponseError{Message: errEmailTaken.Error()}
+		}
+	}
+
+	return fmt.Errorf("failed to create user: %w", err)
+}
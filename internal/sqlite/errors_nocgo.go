@@ -0,0 +1,11 @@
+//go:build !cgo
+
+package sqlite
+
+import "fmt"
+
+// insertError wraps err. Without cgo the driver is a stub that fails to
+// open any file, so no insert reaches a constraint.
+func insertError(err error) error {
+	return fmt.Errorf("failed to create user: %w", err)
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var errSchemaTooNew = errors.New("database schema is newer than this binary")
+
+// migrations are applied in order, once each; the number applied is kept in
+// PRAGMA user_version. Existing entries must never change.
+var migrations = []string{
+	`CREATE TABLE users (
+		id       INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT NOT NULL UNIQUE,
+		password TEXT NOT NULL,
+		email    TEXT NOT NULL UNIQUE COLLATE NOCASE
+	)`,
+	`ALTER TABLE users ADD COLUMN disabled INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user'`,
+}
+
+// migrate applies the migrations db does not have yet, each in its own
+// transaction. The transactions are immediate, see Open, so the version is
+// read again under the write lock and two processes opening the same file
+// never apply a migration twice.
+func migrate(ctx context.Context, db *sql.DB) (err error) {
+	for applied := true; applied; {
+		if applied, err = apply(ctx, db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply applies the next migration db does not have, applied is false when
+// it has them all.
+func apply(ctx context.Context, db *sql.DB) (applied bool, err error) {
+	var version int
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin migration: %w", err)
+	}
+
+	defer func() {
+		if err != nil || !applied {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = tx.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if version > len(migrations) {
+		return false, fmt.Errorf("%w: version %d", errSchemaTooNew, version)
+	}
+
+	if version == len(migrations) {
+		return false, nil
+	}
+
+	if _, err = tx.ExecContext(ctx, migrations[version]); err != nil {
+		return false, fmt.Errorf("failed to apply migration %d: %w", version+1, err)
+	}
+
+	// PRAGMA does not take parameters, version is ours.
+	if _, err = tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+		return false, fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit migration %d: %w", version+1, err)
+	}
+
+	return true, nil
+}
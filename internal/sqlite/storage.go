@@ -0,0 +1,204 @@
+// Package sqlite keeps the users in a local SQLite file, in place of the
+// storage service, for small deployments.
+package sqlite
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"app/internal/backend"
+	"app/internal/entity"
+
+	// The driver registers itself as "sqlite3".
+	_ "github.com/mattn/go-sqlite3"
+)
+
+var (
+	errUserNotFound  = errors.New("user not found")
+	errUsernameTaken = errors.New("username already exists")
+	errEmailTaken    = errors.New("email already exists")
+	errCredentials   = errors.New("invalid username or password")
+)
+
+// Storage is a backend.StorageClient on a SQLite file. Like the storage
+// service, errors about the users are backend.ResponseError and the others,
+// such as a failing disk, are not.
+type Storage struct {
+	db *sql.DB
+}
+
+var _ backend.StorageClient = (*Storage)(nil)
+
+// Open opens the SQLite file at path, creating it when missing, and brings
+// its schema up to date. Transactions take the write lock as they begin.
+func Open(ctx context.Context, path string) (storage *Storage, err error) {
+	db, err := sql.Open("sqlite3", "file:"+(&url.URL{Path: path}).EscapedPath()+
+		"?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	// SQLite takes one writer at a time, a single connection keeps writes
+	// from failing as busy.
+	db.SetMaxOpenConns(1)
+
+	if err = migrate(ctx, db); err != nil {
+		db.Close()
+
+		return nil, fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+
+	return &Storage{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Storage) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+
+	return nil
+}
+
+// CreateUser inserts user, whose username and email must be free.
+func (s *Storage) CreateUser(ctx context.Context, user entity.UsernamePasswordEmailRequest) (err error) {
+	if _, err = s.db.ExecContext(
+		ctx,
+		"INSERT INTO users (username, password, email) VALUES (?, ?, ?)",
+		user.Username, user.Password, user.Email,
+	); err != nil {
+		return insertError(err)
+	}
+
+	return nil
+}
+
+// GetIDByUsername ...
+func (s *Storage) GetIDByUsername(ctx context.Context, username string) (id int, err error) {
+	err = s.db.QueryRowContext(ctx, "SELECT id FROM users WHERE username = ?", username).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetUserByCredentials ...
+func (s *Storage) GetUserByCredentials(ctx context.Context, username, password string) (user entity.User, err error) {
+	user, err = s.getUser(ctx, "username = ?", username)
+	if errors.Is(err, backend.ErrResponse) {
+		return entity.User{}, &backend.ResponseError{Message: errCredentials.Error()}
+	}
+
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) != 1 {
+		return entity.User{}, &backend.ResponseError{Message: errCredentials.Error()}
+	}
+
+	return user, nil
+}
+
+// GetUserByID ...
+func (s *Storage) GetUserByID(ctx context.Context, id int) (user entity.User, err error) {
+	return s.getUser(ctx, "id = ?", id)
+}
+
+// UpdatePassword ...
+func (s *Storage) UpdatePassword(ctx context.Context, id int, password string) (err error) {
+	return s.update(ctx, "UPDATE users SET password = ? WHERE id = ?", password, id)
+}
+
+// SetDisabled ...
+func (s *Storage) SetDisabled(ctx context.Context, id int, disabled bool) (err error) {
+	return s.update(ctx, "UPDATE users SET disabled = ? WHERE id = ?", disabled, id)
+}
+
+// SetRole ...
+func (s *Storage) SetRole(ctx context.Context, id int, role string) (err error) {
+	return s.update(ctx, "UPDATE users SET role = ? WHERE id = ?", role, id)
+}
+
+// DeleteUser ...
+func (s *Storage) DeleteUser(ctx context.Context, id int) (err error) {
+	return s.update(ctx, "DELETE FROM users WHERE id = ?", id)
+}
+
+// ListUsers returns every user ordered by ID, whatever the options, like
+// the storage service; the gateway pages them.
+func (s *Storage) ListUsers(
+	ctx context.Context,
+	_ entity.ListUsersOptions,
+) (page entity.UsersPage, paged bool, err error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT id, username, password, email, disabled, role FROM users ORDER BY id")
+	if err != nil {
+		return entity.UsersPage{}, false, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	defer rows.Close()
+
+	page.Users = []entity.User{}
+
+	for rows.Next() {
+		var user entity.User
+
+		if err = rows.Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.Disabled, &user.Role); err != nil {
+			return entity.UsersPage{}, false, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		page.Users = append(page.Users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return entity.UsersPage{}, false, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return page, false, nil
+}
+
+// getUser returns the user matching where.
+func (s *Storage) getUser(ctx context.Context, where string, args ...any) (user entity.User, err error) {
+	err = s.db.QueryRowContext(
+		ctx,
+		"SELECT id, username, password, email, disabled, role FROM users WHERE "+where,
+		args...,
+	).Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.Disabled, &user.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.User{}, &backend.ResponseError{Message: errUserNotFound.Error()}
+	}
+
+	if err != nil {
+		return entity.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// update executes query, which must change one user.
+func (s *Storage) update(ctx context.Context, query string, args ...any) (err error) {
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if affected == 0 {
+		return &backend.ResponseError{Message: errUserNotFound.Error()}
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"app/internal/backend"
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/service"
+	"app/internal/sqlite"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStorageTest(t *testing.T) (storage *sqlite.Storage, file string) {
+	t.Helper()
+
+	file = filepath.Join(t.TempDir(), "users.db")
+
+	storage, err := sqlite.Open(context.Background(), file)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = storage.Close() })
+
+	return storage, file
+}
+
+func TestStorageCreateUser(t *testing.T) {
+	t.Parallel()
+
+	user := entity.UsernamePasswordEmailRequest{
+		Username: mock.UsernameTest,
+		Password: mock.PasswordTest,
+		Email:    mock.EmailTest,
+	}
+
+	for _, tt := range []struct {
+		name   string
+		in     entity.UsernamePasswordEmailRequest
+		outErr string
+	}{
+		{
+			name: mock.NameNoError,
+			in:   entity.UsernamePasswordEmailRequest{Username: "other", Email: "other@email.com"},
+		},
+		{
+			name:   "ErrorUsername",
+			in:     entity.UsernamePasswordEmailRequest{Username: mock.UsernameTest, Email: "other@email.com"},
+			outErr: "username already exists",
+		},
+		{
+			name:   "ErrorEmail",
+			in:     entity.UsernamePasswordEmailRequest{Username: "other", Email: "EMAIL@email.com"},
+			outErr: "email already exists",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage, _ := newStorageTest(t)
+			assert.Nil(t, storage.CreateUser(context.Background(), user))
+
+			err := storage.CreateUser(context.Background(), tt.in)
+			if tt.outErr != "" {
+				assert.ErrorIs(t, err, backend.ErrResponse)
+				assert.EqualError(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage, file := newStorageTest(t)
+
+	assert.Nil(t, storage.CreateUser(ctx, entity.UsernamePasswordEmailRequest{
+		Username: mock.UsernameTest,
+		Password: mock.PasswordTest,
+		Email:    mock.EmailTest,
+	}))
+	assert.Nil(t, storage.CreateUser(ctx, entity.UsernamePasswordEmailRequest{Username: "other", Email: "o@email.com"}))
+
+	id, err := storage.GetIDByUsername(ctx, mock.UsernameTest)
+	assert.Nil(t, err)
+	assert.Equal(t, mock.IDTest, id)
+
+	id, err = storage.GetIDByUsername(ctx, "missing")
+	assert.Nil(t, err)
+	assert.Zero(t, id)
+
+	user, err := storage.GetUserByCredentials(ctx, mock.UsernameTest, mock.PasswordTest)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.User{
+		ID:       mock.IDTest,
+		Username: mock.UsernameTest,
+		Password: mock.PasswordTest,
+		Email:    mock.EmailTest,
+		Role:     entity.RoleUser,
+	}, user)
+
+	for _, credentials := range [][2]string{{mock.UsernameTest, "wrong"}, {"missing", mock.PasswordTest}} {
+		_, err = storage.GetUserByCredentials(ctx, credentials[0], credentials[1])
+		assert.ErrorIs(t, err, backend.ErrResponse)
+		assert.EqualError(t, err, "invalid username or password")
+	}
+
+	assert.Nil(t, storage.UpdatePassword(ctx, mock.IDTest, "hash"))
+	assert.ErrorIs(t, storage.UpdatePassword(ctx, 3, "hash"), backend.ErrResponse)
+
+	user, err = storage.GetUserByID(ctx, mock.IDTest)
+	assert.Nil(t, err)
+	assert.Equal(t, "hash", user.Password)
+
+	assert.Nil(t, storage.SetDisabled(ctx, mock.IDTest, true))
+	assert.ErrorIs(t, storage.SetDisabled(ctx, 3, true), backend.ErrResponse)
+	assert.Nil(t, storage.SetRole(ctx, mock.IDTest, entity.RoleAdmin))
+	assert.ErrorIs(t, storage.SetRole(ctx, 3, entity.RoleAdmin), backend.ErrResponse)
+
+	user, err = storage.GetUserByID(ctx, mock.IDTest)
+	assert.Nil(t, err)
+	assert.True(t, user.Disabled)
+	assert.Equal(t, entity.RoleAdmin, user.Role)
+
+	assert.Nil(t, storage.DeleteUser(ctx, mock.IDTest))
+	assert.ErrorIs(t, storage.DeleteUser(ctx, mock.IDTest), backend.ErrResponse)
+
+	_, err = storage.GetUserByID(ctx, mock.IDTest)
+	assert.EqualError(t, err, "user not found")
+
+	page, paged, err := storage.ListUsers(ctx, entity.ListUsersOptions{Limit: 1})
+	assert.Nil(t, err)
+	assert.False(t, paged)
+	assert.Equal(t, []entity.User{{ID: 2, Username: "other", Email: "o@email.com", Role: entity.RoleUser}}, page.Users)
+
+	// The users outlive the process.
+	assert.Nil(t, storage.Close())
+
+	reopened, err := sqlite.Open(ctx, file)
+	assert.Nil(t, err)
+
+	defer reopened.Close()
+
+	id, err = reopened.GetIDByUsername(ctx, "other")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, id)
+
+	// Failing to reach the database is not an answer about the user.
+	_, err = storage.GetUserByID(ctx, 2)
+	assert.NotErrorIs(t, err, backend.ErrResponse)
+}
+
+func TestOpenMigrations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	_, file := newStorageTest(t)
+
+	db, err := sql.Open("sqlite3", file)
+	assert.Nil(t, err)
+
+	defer db.Close()
+
+	var version int
+
+	assert.Nil(t, db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version))
+	assert.Equal(t, 3, version)
+
+	// Opening again applies nothing.
+	storage, err := sqlite.Open(ctx, file)
+	assert.Nil(t, err)
+	assert.Nil(t, storage.Close())
+
+	_, err = db.ExecContext(ctx, "PRAGMA user_version = 99")
+	assert.Nil(t, err)
+
+	_, err = sqlite.Open(ctx, file)
+	assert.ErrorContains(t, err, "database schema is newer than this binary")
+
+	_, err = sqlite.Open(ctx, t.TempDir())
+	assert.NotNil(t, err)
+}
+
+func TestOpenConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "users.db")
+	errs := make(chan error, 4)
+
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			storage, err := sqlite.Open(ctx, file)
+			if err == nil {
+				err = storage.Close()
+			}
+
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < cap(errs); i++ {
+		assert.Nil(t, <-errs)
+	}
+}
+
+func TestServiceSQLite(t *testing.T) {
+	t.Parallel()
+
+	storage, _ := newStorageTest(t)
+	svc := service.NewServiceWithBackends(storage, nil, &service.InfoServices{})
+
+	report, err := svc.ImportUsers(context.Background(), []entity.ImportRow{
+		{User: entity.UsernamePasswordEmailRequest{Username: "first", Password: mock.PasswordTest, Email: "f@email.com"}},
+		{User: entity.UsernamePasswordEmailRequest{Username: "first", Password: mock.PasswordTest, Email: "x@email.com"}},
+		{User: entity.UsernamePasswordEmailRequest{Username: "second", Password: mock.PasswordTest, Email: "f@email.com"}},
+	}, entity.ImportOptions{Concurrency: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 1, report.Failed)
+
+	page, err := svc.ListUsers(entity.ListUsersOptions{Sort: "-" + service.SortByUsername})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, *page.Total)
+	assert.Equal(t, "first", page.Users[0].Username)
+}
@@ -0,0 +1,268 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/transport"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeRequestWithHeaderAndID(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		inID   string
+		inAuth string
+		outErr string
+		outID  int
+	}{
+		{
+			name:   mock.NameNoError,
+			inID:   "1",
+			inAuth: mock.TokenTest,
+			outID:  mock.IDTest,
+		},
+		{
+			name:   "ErrorHeader",
+			inID:   "1",
+			outErr: "failed to get header",
+		},
+		{
+			name:   "ErrorID",
+			inID:   "one",
+			inAuth: mock.TokenTest,
+			outErr: "failed to get id",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/users/"+tt.inID, nil)
+			req.Header.Set("Authorization", tt.inAuth)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.inID})
+
+			r, err := transport.DecodeRequestWithHeaderAndID(entity.TokenIDRequest{})(context.TODO(), req)
+			if tt.outErr != "" {
+				assert.ErrorContains(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, entity.TokenIDRequest{Token: tt.inAuth, ID: tt.outID}, r)
+		})
+	}
+}
+
+func TestDecodeListUsersRequest(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		inURL  string
+		inAuth string
+		outErr string
+		out    entity.ListUsersOptions
+	}{
+		{
+			name:   mock.NameNoError,
+			inURL:  "/users?limit=10&offset=5&sort=-username&usernamePrefix=al&emailDomain=example.com&cursor=c",
+			inAuth: mock.TokenTest,
+			out: entity.ListUsersOptions{
+				Limit:          10,
+				Offset:         5,
+				Sort:           "-username",
+				UsernamePrefix: "al",
+				EmailDomain:    "example.com",
+				Cursor:         "c",
+			},
+		},
+		{
+			name:   mock.NameNoError + "WithoutQuery",
+			inURL:  "/users",
+			inAuth: mock.TokenTest,
+		},
+		{
+			name:   "ErrorHeader",
+			inURL:  "/users",
+			outErr: "failed to get header",
+		},
+		{
+			name:   "ErrorLimit",
+			inURL:  "/users?limit=ten",
+			inAuth: mock.TokenTest,
+			outErr: "failed to get query",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tt.inURL, nil)
+			req.Header.Set("Authorization", tt.inAuth)
+
+			r, err := transport.DecodeListUsersRequest(entity.ListUsersRequest{})(context.TODO(), req)
+			if tt.outErr != "" {
+				assert.ErrorContains(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, entity.ListUsersRequest{Token: tt.inAuth, ListUsersOptions: tt.out}, r)
+		})
+	}
+}
+
+func TestDecodeImportUsersRequest(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name          string
+		inURL         string
+		inAuth        string
+		inContentType string
+		inBody        string
+		outErr        string
+		outParseErr   string
+		outOptions    entity.ImportOptions
+		outRows       int
+	}{
+		{
+			name:          mock.NameNoError,
+			inURL:         "/users/import?dryRun=true&concurrency=8",
+			inAuth:        mock.TokenTest,
+			inContentType: "text/csv",
+			inBody:        "username,password,email\nusername,password,email@email.com\n",
+			outOptions:    entity.ImportOptions{DryRun: true, Concurrency: 8},
+			outRows:       1,
+		},
+		{
+			name:          "ErrorBodyTooLarge",
+			inURL:         "/users/import",
+			inAuth:        mock.TokenTest,
+			inContentType: "text/csv",
+			inBody:        "username,password,email\n" + strings.Repeat("username,password,email@email.com\n", 64),
+			outParseErr:   "request body too large",
+		},
+		{
+			name:          "ErrorMalformedBody",
+			inURL:         "/users/import",
+			inAuth:        mock.TokenTest,
+			inContentType: "text/csv",
+			inBody:        "username,password\n",
+			outParseErr:   "failed to decode request: missing column",
+		},
+		{
+			name:   "ErrorHeader",
+			inURL:  "/users/import",
+			outErr: "failed to get header",
+		},
+		{
+			name:   "ErrorDryRun",
+			inURL:  "/users/import?dryRun=maybe",
+			inAuth: mock.TokenTest,
+			outErr: "failed to get query",
+		},
+		{
+			name:          "ErrorContentType",
+			inURL:         "/users/import",
+			inAuth:        mock.TokenTest,
+			inContentType: "application/xml",
+			outErr:        "unknown import format",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, tt.inURL, strings.NewReader(tt.inBody))
+			req.Header.Set("Authorization", tt.inAuth)
+			req.Header.Set("Content-Type", tt.inContentType)
+
+			r, err := transport.DecodeImportUsersRequest(entity.ImportUsersRequest{}, 1024)(context.TODO(), req)
+			if tt.outErr != "" {
+				assert.ErrorContains(t, err, tt.outErr)
+
+				return
+			}
+
+			result, ok := r.(entity.ImportUsersRequest)
+			assert.True(t, ok)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.outOptions, result.ImportOptions)
+
+			rows, err := result.Parse()
+			if tt.outParseErr != "" {
+				assert.ErrorContains(t, err, tt.outParseErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Len(t, rows, tt.outRows)
+		})
+	}
+}
+
+func TestDecodeChangePasswordRequest(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		inAuth string
+		inBody string
+		outErr string
+		out    entity.ChangePasswordRequest
+	}{
+		{
+			name:   mock.NameNoError,
+			inAuth: mock.TokenTest,
+			inBody: `{"oldPassword":"password","newPassword":"battery-staple-97"}`,
+			out: entity.ChangePasswordRequest{
+				Token:       mock.TokenTest,
+				OldPassword: mock.PasswordTest,
+				NewPassword: "battery-staple-97",
+			},
+		},
+		{
+			name:   "ErrorHeader",
+			inBody: `{}`,
+			outErr: "failed to get header",
+		},
+		{
+			name:   "ErrorBody",
+			inAuth: mock.TokenTest,
+			inBody: `{`,
+			outErr: "failed to decode request",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPut, "/profile/password", strings.NewReader(tt.inBody))
+			req.Header.Set("Authorization", tt.inAuth)
+
+			r, err := transport.DecodeChangePasswordRequest(entity.ChangePasswordRequest{}, 0)(context.TODO(), req)
+			if tt.outErr != "" {
+				assert.ErrorContains(t, err, tt.outErr)
+
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.out, r)
+		})
+	}
+}
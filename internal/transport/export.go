@@ -0,0 +1,198 @@
+package transport
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"app/internal/entity"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+// Content types accepted by EncodeUsersExportResponse.
+const (
+	ContentTypeNDJSON string = "application/x-ndjson"
+	ContentTypeCSV    string = "text/csv"
+
+	exportFlushEvery int = 100
+)
+
+// exportedUser is the redacted form of entity.User written by exports.
+type exportedUser struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	ID       int    `json:"id"`
+	Disabled bool   `json:"disabled"`
+}
+
+type usersExportWriter interface {
+	Write(entity.User) error
+	Flush() error
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+// EncodeUsersExportResponse streams an entity.UsersExport as newline
+// delimited JSON, or as CSV when the Accept header asks for it. The Accept
+// header is read from the context, see httptransport.PopulateRequestContext.
+// Any other response is encoded by EncodeResponse.
+//
+// Nothing is written before the first user, so an export failing that early
+// is answered with an error as any other response. A failure after that
+// aborts the connection, the client sees a truncated response instead of a
+// complete one.
+func EncodeUsersExportResponse(ctx context.Context, w http.ResponseWriter, response any) (err error) {
+	var (
+		writer usersExportWriter
+		rows   int
+	)
+
+	export, ok := response.(entity.UsersExport)
+	if !ok {
+		return EncodeResponse(ctx, w, response)
+	}
+
+	accept, _ := ctx.Value(httptransport.ContextKeyRequestAccept).(string)
+	flusher, _ := w.(http.Flusher)
+
+	err = export.Each(func(user entity.User) (err error) {
+		if writer == nil {
+			if writer, err = startUsersExport(w, accept); err != nil {
+				return err
+			}
+		}
+
+		if err = writer.Write(user); err != nil {
+			return err
+		}
+
+		if rows++; rows%exportFlushEvery == 0 {
+			if err = writer.Flush(); err != nil {
+				return err
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if writer == nil {
+			return EncodeResponse(ctx, w, entity.ErrorResponse{Err: err.Error(), Failure: entity.Failure{Cause: err}})
+		}
+
+		// The status line is already sent, the server closes the connection
+		// without ending the body.
+		panic(http.ErrAbortHandler)
+	}
+
+	if writer == nil {
+		if writer, err = startUsersExport(w, accept); err != nil {
+			return err
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		return err
+	}
+
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+// startUsersExport writes the status line and headers of an export, and the
+// CSV header when accept asks for CSV.
+func startUsersExport(w http.ResponseWriter, accept string) (writer usersExportWriter, err error) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if !strings.Contains(accept, ContentTypeCSV) {
+		w.Header().Set("Content-Type", ContentTypeNDJSON)
+		w.WriteHeader(http.StatusOK)
+
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	}
+
+	w.Header().Set("Content-Type", ContentTypeCSV)
+	w.WriteHeader(http.StatusOK)
+
+	csvW := &csvWriter{writer: csv.NewWriter(w)}
+
+	if err = csvW.writer.Write(csvHeader()); err != nil {
+		return nil, fmt.Errorf("failed to encode header: %w", err)
+	}
+
+	return csvW, nil
+}
+
+func csvHeader() []string {
+	return []string{"id", "username", "email", "role", "disabled"}
+}
+
+func redactUser(user entity.User) exportedUser {
+	return exportedUser{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Role:     user.Role,
+		Disabled: user.Disabled,
+	}
+}
+
+// Write ...
+func (n *ndjsonWriter) Write(user entity.User) (err error) {
+	if err = n.encoder.Encode(redactUser(user)); err != nil {
+		return fmt.Errorf("failed to encode user: %w", err)
+	}
+
+	return nil
+}
+
+// Flush ...
+func (n *ndjsonWriter) Flush() (err error) {
+	return nil
+}
+
+// Write ...
+func (c *csvWriter) Write(user entity.User) (err error) {
+	exported := redactUser(user)
+
+	if err = c.writer.Write([]string{
+		strconv.Itoa(exported.ID),
+		exported.Username,
+		exported.Email,
+		exported.Role,
+		strconv.FormatBool(exported.Disabled),
+	}); err != nil {
+		return fmt.Errorf("failed to encode user: %w", err)
+	}
+
+	return nil
+}
+
+// Flush ...
+func (c *csvWriter) Flush() (err error) {
+	c.writer.Flush()
+
+	if err = c.writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush users: %w", err)
+	}
+
+	return nil
+}
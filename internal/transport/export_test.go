@@ -0,0 +1,147 @@
+package transport_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/transport"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+var errExportTest = errors.New("export failed")
+
+func TestEncodeUsersExportResponse(t *testing.T) {
+	t.Parallel()
+
+	users := []entity.User{
+		{ID: 1, Username: mock.UsernameTest, Password: mock.PasswordTest, Email: mock.EmailTest, Role: entity.RoleUser},
+		{ID: 2, Username: "admin", Password: mock.PasswordTest, Email: "admin@email.com", Role: entity.RoleAdmin},
+	}
+
+	for _, tt := range []struct {
+		in             any
+		name           string
+		inAccept       string
+		outContentType string
+		outBody        string
+		outAbort       bool
+	}{
+		{
+			name:           mock.NameNoError + "NDJSON",
+			in:             newExport(users, nil),
+			outContentType: transport.ContentTypeNDJSON,
+			outBody: `{"username":"username","email":"email@email.com","role":"user","id":1,"disabled":false}
+{"username":"admin","email":"admin@email.com","role":"admin","id":2,"disabled":false}
+`,
+		},
+		{
+			name:           mock.NameNoError + "CSV",
+			in:             newExport(users, nil),
+			inAccept:       "text/csv; charset=utf-8",
+			outContentType: transport.ContentTypeCSV,
+			outBody:        "id,username,email,role,disabled\n1,username,email@email.com,user,false\n2,admin,admin@email.com,admin,false\n",
+		},
+		{
+			name:           mock.NameNoError + "EmptyCSV",
+			in:             newExport(nil, nil),
+			inAccept:       transport.ContentTypeCSV,
+			outContentType: transport.ContentTypeCSV,
+			outBody:        "id,username,email,role,disabled\n",
+		},
+		{
+			name:    "ErrorBeforeFirstUser",
+			in:      newExport(nil, errExportTest),
+			outBody: `{"err":"export failed"}` + "\n",
+		},
+		{
+			name:           "ErrorAfterFirstUser",
+			in:             newExport(users[:1], errExportTest),
+			outContentType: transport.ContentTypeNDJSON,
+			outBody: `{"username":"username","email":"email@email.com","role":"user","id":1,"disabled":false}
+`,
+			outAbort: true,
+		},
+		{
+			name:    "NotExport",
+			in:      entity.ErrorResponse{Err: "forbidden"},
+			outBody: `{"err":"forbidden"}` + "\n",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			ctx := context.WithValue(context.TODO(), httptransport.ContextKeyRequestAccept, tt.inAccept)
+
+			if tt.outAbort {
+				assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+					_ = transport.EncodeUsersExportResponse(ctx, w, tt.in)
+				})
+			} else {
+				assert.Nil(t, transport.EncodeUsersExportResponse(ctx, w, tt.in))
+			}
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			if tt.outContentType != "" {
+				assert.Equal(t, tt.outContentType, w.Header().Get("Content-Type"))
+			}
+
+			assert.Equal(t, tt.outBody, w.Body.String())
+			assert.NotContains(t, w.Body.String(), mock.PasswordTest)
+		})
+	}
+}
+
+func newExport(users []entity.User, err error) entity.UsersExport {
+	return entity.UsersExport{
+		Each: func(yield func(entity.User) error) error {
+			for _, user := range users {
+				if err := yield(user); err != nil {
+					return err
+				}
+			}
+
+			return err
+		},
+	}
+}
+
+func TestEncodeResponseStatusCode(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		in        any
+		name      string
+		outStatus int
+	}{
+		{
+			name:      mock.NameNoError,
+			in:        entity.ErrorResponse{},
+			outStatus: http.StatusOK,
+		},
+		{
+			name:      "StatusCoder",
+			in:        entity.ValidationErrorResponse{Err: "invalid request"},
+			outStatus: http.StatusBadRequest,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+
+			assert.Nil(t, transport.EncodeResponse(context.TODO(), w, tt.in))
+			assert.Equal(t, tt.outStatus, w.Code)
+		})
+	}
+}
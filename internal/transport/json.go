@@ -0,0 +1,128 @@
+package transport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultMaxBodyBytes bounds JSON bodies when the decoders get no limit.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+var (
+	errMalformedBody        = errors.New("failed to decode request")
+	errBodyTooLarge         = errors.New("request body too large")
+	errUnsupportedMediaType = errors.New("unsupported content type")
+	errMultipleValues       = errors.New("body must contain a single JSON value")
+)
+
+// RequestError is a decode error with the status it must be answered with.
+// go-kit's default error encoder writes it as {"err": "..."}.
+type RequestError struct {
+	Err    error
+	Status int
+}
+
+func (e *RequestError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode implements the go-kit StatusCoder.
+func (e *RequestError) StatusCode() int {
+	return e.Status
+}
+
+// MarshalJSON implements json.Marshaler, which the go-kit error encoder
+// prefers over plain text.
+func (e *RequestError) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		Err string `json:"err"`
+	}{Err: e.Err.Error()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode error: %w", err)
+	}
+
+	return data, nil
+}
+
+// decodeJSON decodes the body of r into v. The body must be a single JSON
+// value of at most maxBodyBytes, without fields v lacks, and the
+// Content-Type, when set, must be JSON.
+func decodeJSON(r *http.Request, v any, maxBodyBytes int64) (err error) {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return &RequestError{
+				Err:    fmt.Errorf("%w: %q, expected application/json", errUnsupportedMediaType, contentType),
+				Status: http.StatusUnsupportedMediaType,
+			}
+		}
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err = decoder.Decode(v); err != nil {
+		return jsonError(err, maxBodyBytes)
+	}
+
+	if err = decoder.Decode(&json.RawMessage{}); !errors.Is(err, io.EOF) {
+		if err == nil {
+			err = errMultipleValues
+		}
+
+		return jsonError(err, maxBodyBytes)
+	}
+
+	return nil
+}
+
+// jsonError turns the errors of encoding/json into messages that point at
+// the problem.
+func jsonError(err error, maxBodyBytes int64) *RequestError {
+	var (
+		tooLarge  *http.MaxBytesError
+		syntax    *json.SyntaxError
+		typeError *json.UnmarshalTypeError
+		message   string
+	)
+
+	switch {
+	case errors.As(err, &tooLarge):
+		return &RequestError{
+			Err:    fmt.Errorf("%w: limit is %d bytes", errBodyTooLarge, maxBodyBytes),
+			Status: http.StatusRequestEntityTooLarge,
+		}
+	case errors.Is(err, io.EOF):
+		message = "body is empty"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		message = "body ends in the middle of a JSON value"
+	case errors.As(err, &syntax):
+		message = fmt.Sprintf("malformed JSON at offset %d", syntax.Offset)
+	case errors.As(err, &typeError):
+		message = fmt.Sprintf("field %q must be %s", typeError.Field, typeError.Type)
+	case errors.Is(err, errMultipleValues):
+		message = errMultipleValues.Error()
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		message = "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	default:
+		message = err.Error()
+	}
+
+	return &RequestError{
+		Err:    fmt.Errorf("%w: %s", errMalformedBody, message),
+		Status: http.StatusBadRequest,
+	}
+}
@@ -0,0 +1,218 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/transport"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+const maxBodyBytesTest int64 = 128
+
+func TestDecodeRequestWithBodyStrict(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name          string
+		inContentType string
+		inBody        string
+		outErr        string
+		outStatus     int
+	}{
+		{
+			name:          mock.NameNoError,
+			inContentType: "application/json; charset=utf-8",
+			inBody:        `{"username":"username","password":"password"}`,
+		},
+		{
+			name:   mock.NameNoError + "WithoutContentType",
+			inBody: `{"username":"username","password":"password"} ` + "\n",
+		},
+		{
+			name:          "ErrorContentType",
+			inContentType: "application/x-www-form-urlencoded",
+			inBody:        `{"username":"username","password":"password"}`,
+			outErr:        `unsupported content type: "application/x-www-form-urlencoded"`,
+			outStatus:     http.StatusUnsupportedMediaType,
+		},
+		{
+			name:      "ErrorTooLarge",
+			inBody:    `{"username":"` + strings.Repeat("u", int(maxBodyBytesTest)) + `"}`,
+			outErr:    "request body too large: limit is 128 bytes",
+			outStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:      "ErrorUnknownField",
+			inBody:    `{"username":"username","password":"password","admin":true}`,
+			outErr:    `unknown field "admin"`,
+			outStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "ErrorMultipleValues",
+			inBody:    `{"username":"username"}{"password":"password"}`,
+			outErr:    "body must contain a single JSON value",
+			outStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "ErrorTrailingGarbage",
+			inBody:    `{"username":"username"} x`,
+			outErr:    "malformed JSON at offset",
+			outStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "ErrorEmpty",
+			outErr:    "body is empty",
+			outStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "ErrorTruncated",
+			inBody:    `{"username":`,
+			outErr:    "body ends in the middle of a JSON value",
+			outStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "ErrorType",
+			inBody:    `{"username":1}`,
+			outErr:    `field "username" must be string`,
+			outStatus: http.StatusBadRequest,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/signin", strings.NewReader(tt.inBody))
+			req.Header.Set("Content-Type", tt.inContentType)
+
+			r, err := transport.DecodeRequestWithBody(entity.UsernamePasswordRequest{}, maxBodyBytesTest)(
+				context.TODO(),
+				req,
+			)
+			if tt.outErr == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, entity.UsernamePasswordRequest{Username: mock.UsernameTest, Password: mock.PasswordTest}, r)
+
+				return
+			}
+
+			var coder httptransport.StatusCoder
+
+			assert.ErrorContains(t, err, tt.outErr)
+			assert.True(t, errors.As(err, &coder))
+			assert.Equal(t, tt.outStatus, coder.StatusCode())
+
+			w := httptest.NewRecorder()
+			httptransport.DefaultErrorEncoder(context.TODO(), err, w)
+			assert.Equal(t, tt.outStatus, w.Code)
+			assert.JSONEq(t, `{"err":`+string(mustMarshal(t, err.Error()))+`}`, w.Body.String())
+		})
+	}
+}
+
+func FuzzDecodeRequestWithBody(f *testing.F) {
+	for _, seed := range []string{
+		`{"username":"username","password":"password","email":"email@email.com"}`,
+		`{"username":"username"}{}`,
+		`{"username":1}`,
+		`{"unknown":null}`,
+		`[]`,
+		`"\ud800"`,
+		``,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, body string) {
+		req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(body))
+
+		r, err := transport.DecodeRequestWithBody(entity.UsernamePasswordEmailRequest{}, maxBodyBytesTest)(
+			context.TODO(),
+			req,
+		)
+		if err != nil {
+			var coder httptransport.StatusCoder
+			if !errors.As(err, &coder) {
+				t.Fatalf("error without status: %v", err)
+			}
+
+			if status := coder.StatusCode(); status != http.StatusBadRequest &&
+				status != http.StatusRequestEntityTooLarge {
+				t.Fatalf("unexpected status %d: %v", status, err)
+			}
+
+			return
+		}
+
+		if int64(len(body)) > maxBodyBytesTest {
+			t.Fatalf("accepted a body of %d bytes", len(body))
+		}
+
+		// A body that decoded must survive a round trip.
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		again, err := transport.DecodeRequestWithBody(entity.UsernamePasswordEmailRequest{}, 0)(
+			context.TODO(),
+			httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(string(data))),
+		)
+		if err != nil || again != r {
+			t.Fatalf("round trip of %q failed: %v", data, err)
+		}
+	})
+}
+
+func FuzzDecodeChangePasswordRequest(f *testing.F) {
+	for _, seed := range []string{
+		`{"oldPassword":"password","newPassword":"battery-staple-97"}`,
+		`{"oldPassword":"password","token":"token"}`,
+		`{`,
+	} {
+		f.Add(seed, "application/json")
+	}
+
+	f.Add(`{}`, "text/plain")
+
+	f.Fuzz(func(t *testing.T, body, contentType string) {
+		req := httptest.NewRequest(http.MethodPut, "/profile/password", strings.NewReader(body))
+		req.Header.Set("Authorization", mock.TokenTest)
+		req.Header.Set("Content-Type", contentType)
+
+		r, err := transport.DecodeChangePasswordRequest(entity.ChangePasswordRequest{}, maxBodyBytesTest)(
+			context.TODO(),
+			req,
+		)
+		if err != nil {
+			var coder httptransport.StatusCoder
+			if !errors.As(err, &coder) {
+				t.Fatalf("error without status: %v", err)
+			}
+
+			return
+		}
+
+		if req, ok := r.(entity.ChangePasswordRequest); !ok || req.Token != mock.TokenTest {
+			t.Fatalf("token not taken from the header: %#v", r)
+		}
+	})
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	assert.Nil(t, err)
+
+	return data
+}
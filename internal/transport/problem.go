@@ -0,0 +1,198 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"app/internal/endpoint"
+	"app/internal/entity"
+	"app/internal/password"
+	"app/internal/service"
+	"app/internal/validation"
+
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+// ContentTypeProblem is the media type of RFC 7807 problem details.
+const ContentTypeProblem string = "application/problem+json"
+
+// ProblemTypeBase prefixes the slugs of the problem type URIs.
+const ProblemTypeBase string = "/problems/"
+
+type problemContextKey struct{}
+
+// problemType describes the problems made from errors that match err.
+type problemType struct {
+	err    error
+	slug   string
+	title  string
+	status int
+}
+
+// problemTypes are matched in order with errors.Is, so wrapping errors go
+// before the ones they wrap.
+func problemTypes() []problemType {
+	return []problemType{
+		{validation.ErrInvalidRequest, "invalid-request", "Invalid request", http.StatusBadRequest},
+		{password.ErrWeakPassword, "weak-password", "Password does not meet the policy", http.StatusBadRequest},
+		{errBodyTooLarge, "body-too-large", "Request body too large", http.StatusRequestEntityTooLarge},
+		{errUnsupportedMediaType, "unsupported-media-type", "Unsupported content type", http.StatusUnsupportedMediaType},
+		{errMalformedBody, "malformed-body", "Malformed request body", http.StatusBadRequest},
+		{errFailedGetHeader, "missing-token", "Missing token", http.StatusUnauthorized},
+		{errMalformedToken, "malformed-token", "Malformed token", http.StatusUnauthorized},
+		{errCSRF, "invalid-csrf-token", "Missing or invalid CSRF token", http.StatusForbidden},
+		{errFailedGetID, "invalid-id", "Invalid user ID", http.StatusBadRequest},
+		{errFailedGetQuery, "invalid-query", "Invalid query parameter", http.StatusBadRequest},
+		{service.ErrInvalidListOptions, "invalid-list-options", "Invalid list options", http.StatusBadRequest},
+		{service.ErrCredentials, "invalid-credentials", "Invalid username or password", http.StatusUnauthorized},
+		{service.ErrTokenNotValid, "invalid-token", "Invalid token", http.StatusUnauthorized},
+		{service.ErrUserDisabled, "user-disabled", "User disabled", http.StatusForbidden},
+		{service.ErrForbidden, "forbidden", "Forbidden", http.StatusForbidden},
+		{service.ErrSessionNotFound, "session-not-found", "Session not found", http.StatusNotFound},
+		{service.ErrNotSupported, "not-supported", "Not supported by the backend", http.StatusNotImplemented},
+		{service.ErrWebServer, "backend-error", "Backend service error", http.StatusBadGateway},
+		{endpoint.ErrRequest, "internal-error", "Internal server error", http.StatusInternalServerError},
+	}
+}
+
+// NegotiateErrorFormat is a go-kit ServerBefore that makes the encoders of
+// this package write errors as problem details when the Accept header
+// prefers application/problem+json. Other clients keep the {"err": ...}
+// bodies.
+func NegotiateErrorFormat(ctx context.Context, r *http.Request) context.Context {
+	if acceptsProblem(r.Header.Get("Accept")) {
+		return context.WithValue(ctx, problemContextKey{}, true)
+	}
+
+	return ctx
+}
+
+// WantsProblem reports whether errors must be written as problem details.
+func WantsProblem(ctx context.Context) (check bool) {
+	check, _ = ctx.Value(problemContextKey{}).(bool)
+
+	return check
+}
+
+// NewProblem describes err, the first matching problem type gives its type,
+// title and status and unknown errors are internal errors.
+func NewProblem(ctx context.Context, err error) (problem entity.Problem) {
+	var (
+		errs  validation.Errors
+		coder httptransport.StatusCoder
+	)
+
+	problem = entity.Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(http.StatusInternalServerError),
+		Status:   http.StatusInternalServerError,
+		Detail:   err.Error(),
+		Instance: RequestIDFromContext(ctx),
+	}
+
+	for _, t := range problemTypes() {
+		if errors.Is(err, t.err) {
+			problem.Type, problem.Title, problem.Status = ProblemTypeBase+t.slug, t.title, t.status
+
+			break
+		}
+	}
+
+	if errors.As(err, &coder) && problem.Type == "about:blank" {
+		problem.Status, problem.Title = coder.StatusCode(), http.StatusText(coder.StatusCode())
+	}
+
+	if errors.As(err, &errs) {
+		problem.Errors = errs
+	}
+
+	return problem
+}
+
+// EncodeError is a go-kit ErrorEncoder writing problem details when
+// negotiated and falling back to httptransport.DefaultErrorEncoder.
+func EncodeError(ctx context.Context, err error, w http.ResponseWriter) {
+	if !WantsProblem(ctx) {
+		httptransport.DefaultErrorEncoder(ctx, err, w)
+
+		return
+	}
+
+	_ = writeProblem(w, NewProblem(ctx, err), err)
+}
+
+// encodeFailure writes the error of a failed response as problem details
+// when negotiated, handled is false when the response must be encoded as is.
+func encodeFailure(ctx context.Context, w http.ResponseWriter, response any) (handled bool, err error) {
+	if !WantsProblem(ctx) {
+		return false, nil
+	}
+
+	switch res := response.(type) {
+	case entity.ValidationErrorResponse:
+		err = validation.Errors(res.Fields)
+
+		return true, writeProblem(w, NewProblem(ctx, err), err)
+	case kitendpoint.Failer:
+		if res.Failed() == nil {
+			return false, nil
+		}
+
+		return true, writeProblem(w, NewProblem(ctx, res.Failed()), res.Failed())
+	default:
+		return false, nil
+	}
+}
+
+// writeProblem writes problem, with the WWW-Authenticate challenge of cause
+// when it is about the token.
+func writeProblem(w http.ResponseWriter, problem entity.Problem, cause error) (err error) {
+	if value := challenge(cause); value != "" && problem.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", value)
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(problem.Status)
+
+	if err = json.NewEncoder(w).Encode(problem); err != nil {
+		return fmt.Errorf("failed to encode problem: %w", err)
+	}
+
+	return nil
+}
+
+// acceptsProblem reports whether accept ranks application/problem+json
+// above application/json.
+func acceptsProblem(accept string) (check bool) {
+	var problemQ, jsonQ float64 = -1, -1
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case ContentTypeProblem:
+			problemQ = q
+		case "application/json":
+			jsonQ = q
+		}
+	}
+
+	return problemQ > 0 && problemQ >= jsonQ
+}
@@ -0,0 +1,234 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/password"
+	"app/internal/service"
+	"app/internal/transport"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateErrorFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name     string
+		inAccept string
+		out      bool
+	}{
+		{name: "Empty"},
+		{name: "JSON", inAccept: "application/json"},
+		{name: "Any", inAccept: "*/*"},
+		{name: "Problem", inAccept: "application/problem+json", out: true},
+		{name: "ProblemFirst", inAccept: "application/problem+json, application/json", out: true},
+		{name: "ProblemLower", inAccept: "application/problem+json;q=0.5, application/json"},
+		{name: "ProblemHigher", inAccept: "application/json;q=0.5, application/problem+json", out: true},
+		{name: "ProblemRefused", inAccept: "application/problem+json;q=0"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept", tt.inAccept)
+
+			assert.Equal(t, tt.out, transport.WantsProblem(transport.NegotiateErrorFormat(context.TODO(), req)))
+		})
+	}
+}
+
+func TestNewProblem(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		in        error
+		name      string
+		outType   string
+		outStatus int
+	}{
+		{
+			name:      "Credentials",
+			in:        service.ErrCredentials,
+			outType:   "/problems/invalid-credentials",
+			outStatus: http.StatusUnauthorized,
+		},
+		{
+			name:      "WrappedBackend",
+			in:        fmt.Errorf("%w:%s", service.ErrWebServer, "user not found"),
+			outType:   "/problems/backend-error",
+			outStatus: http.StatusBadGateway,
+		},
+		{
+			name:      "NotSupported",
+			in:        fmt.Errorf("%w:%s", service.ErrNotSupported, "PUT /user/disabled answered 405"),
+			outType:   "/problems/not-supported",
+			outStatus: http.StatusNotImplemented,
+		},
+		{
+			name:      "WeakPassword",
+			in:        &password.PolicyError{Violations: []string{"is too easy to guess"}},
+			outType:   "/problems/weak-password",
+			outStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Unknown",
+			in:        mock.ErrWebServer,
+			outType:   "about:blank",
+			outStatus: http.StatusInternalServerError,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			problem := transport.NewProblem(context.TODO(), tt.in)
+
+			assert.Equal(t, tt.outType, problem.Type)
+			assert.Equal(t, tt.outStatus, problem.Status)
+			assert.Equal(t, tt.in.Error(), problem.Detail)
+			assert.NotEmpty(t, problem.Title)
+		})
+	}
+}
+
+func TestEncodeResponseProblem(t *testing.T) {
+	t.Parallel()
+
+	failed := entity.TokenErrorResponse{
+		Err:     service.ErrCredentials.Error(),
+		Failure: entity.Failure{Cause: service.ErrCredentials},
+	}
+
+	for _, tt := range []struct {
+		in             any
+		name           string
+		inAccept       string
+		outContentType string
+		outBody        string
+		outStatus      int
+	}{
+		{
+			name:      "Legacy",
+			in:        failed,
+			outStatus: http.StatusOK,
+			outBody:   `{"token":"","err":"invalid username or password"}`,
+		},
+		{
+			name:           "Problem",
+			in:             failed,
+			inAccept:       transport.ContentTypeProblem,
+			outContentType: transport.ContentTypeProblem,
+			outStatus:      http.StatusUnauthorized,
+			outBody: `{
+				"type":"/problems/invalid-credentials",
+				"title":"Invalid username or password",
+				"status":401,
+				"detail":"invalid username or password",
+				"instance":"request-id"
+			}`,
+		},
+		{
+			name:      "ProblemWithoutFailure",
+			in:        entity.TokenErrorResponse{Token: mock.TokenTest},
+			inAccept:  transport.ContentTypeProblem,
+			outStatus: http.StatusOK,
+			outBody:   `{"token":"token"}`,
+		},
+		{
+			name: "ProblemValidation",
+			in: entity.ValidationErrorResponse{
+				Err:    "invalid request",
+				Fields: []entity.FieldError{{Field: "email", Message: "must be an email address"}},
+			},
+			inAccept:       transport.ContentTypeProblem,
+			outContentType: transport.ContentTypeProblem,
+			outStatus:      http.StatusBadRequest,
+			outBody: `{
+				"type":"/problems/invalid-request",
+				"title":"Invalid request",
+				"status":400,
+				"detail":"invalid request: email: must be an email address",
+				"instance":"request-id",
+				"errors":[{"field":"email","message":"must be an email address"}]
+			}`,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			handler := transport.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := transport.NegotiateErrorFormat(r.Context(), r)
+
+				assert.Nil(t, transport.EncodeResponse(ctx, w, tt.in))
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/signin", nil)
+			req.Header.Set("Accept", tt.inAccept)
+			req.Header.Set(transport.HeaderRequestID, "request-id")
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.outStatus, w.Code)
+			assert.JSONEq(t, tt.outBody, w.Body.String())
+			assert.Equal(t, "request-id", w.Header().Get(transport.HeaderRequestID))
+
+			if tt.outContentType != "" {
+				assert.Equal(t, tt.outContentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestEncodeError(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodPost, "/signin", strings.NewReader(`{"admin":true}`))
+	_, err := transport.DecodeRequestWithBody(entity.UsernamePasswordRequest{}, 0)(context.TODO(), req)
+	assert.Error(t, err)
+
+	w := httptest.NewRecorder()
+	transport.EncodeError(context.TODO(), err, w)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"err":"failed to decode request: unknown field \"admin\""}`, w.Body.String())
+
+	req.Header.Set("Accept", transport.ContentTypeProblem)
+
+	w = httptest.NewRecorder()
+	transport.EncodeError(transport.NegotiateErrorFormat(context.TODO(), req), err, w)
+
+	var problem entity.Problem
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Nil(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, "/problems/malformed-body", problem.Type)
+}
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	var seen string
+
+	handler := transport.RequestID(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		seen = transport.RequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(transport.HeaderRequestID, "bad id\n")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, w.Header().Get(transport.HeaderRequestID))
+}
@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// HeaderRequestID carries the ID of a request, clients may set it and the
+// server always echoes it.
+const HeaderRequestID string = "X-Request-ID"
+
+const maxRequestIDLength int = 128
+
+type requestIDContextKey struct{}
+
+// RequestID is an HTTP middleware that keeps the X-Request-ID of the request,
+// or generates one, in the context and the response headers.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(HeaderRequestID, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDContextKey{}, id)))
+	})
+}
+
+// RequestIDFromContext returns the ID set by RequestID, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+
+	return id
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+
+	// crypto/rand.Read only fails when the OS source does, an empty ID is
+	// fine then.
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(id)
+}
+
+// validRequestID accepts the visible ASCII IDs that are safe to echo.
+func validRequestID(id string) (check bool) {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
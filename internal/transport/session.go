@@ -0,0 +1,205 @@
+package transport
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"app/internal/entity"
+
+	kitendpoint "github.com/go-kit/kit/endpoint"
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+// CSRF protections of the browser sessions.
+const (
+	// CSRFDoubleSubmit compares the header with a random value sent in a
+	// cookie readable by the page.
+	CSRFDoubleSubmit string = "double-submit"
+	// CSRFSynchronizer compares the header with an HMAC of the session
+	// token, given to the page on sign-in.
+	CSRFSynchronizer string = "synchronizer"
+)
+
+const csrfTokenBytes int = 32
+
+var errCSRF = errors.New("missing or invalid CSRF token")
+
+// BrowserSession keeps the token of browsers in a Secure, HttpOnly cookie
+// instead of the page, so state-changing requests made with that cookie
+// must prove they come from the page with a CSRF token.
+type BrowserSession struct {
+	// Cookie names the session cookie, "" disables browser sessions.
+	Cookie   string
+	Domain   string
+	Path     string
+	SameSite http.SameSite
+	// MaxAge is the lifetime of the cookies, 0 makes them last as long as
+	// the browser.
+	MaxAge time.Duration
+	// Insecure drops the Secure attribute, for development over plain HTTP.
+	Insecure bool
+
+	// CSRF is CSRFDoubleSubmit, the default, or CSRFSynchronizer.
+	CSRF       string
+	CSRFCookie string
+	CSRFHeader string
+	// CSRFKey signs the synchronizer tokens.
+	CSRFKey []byte
+}
+
+// Start wraps the encoder of the sign-in route: successful sign-ins also set
+// the session cookie and send the CSRF token in CSRFHeader.
+func (s BrowserSession) Start(next httptransport.EncodeResponseFunc) httptransport.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response any) (err error) {
+		res, ok := response.(entity.TokenErrorResponse)
+		if !ok || res.Token == "" || failed(response) {
+			return next(ctx, w, response)
+		}
+
+		csrf := s.synchronizerToken(res.Token)
+		if s.csrfMode() == CSRFDoubleSubmit {
+			if csrf, err = newCSRFToken(); err != nil {
+				return err
+			}
+
+			http.SetCookie(w, s.cookie(s.csrfCookie(), csrf, false))
+		}
+
+		http.SetCookie(w, s.cookie(s.Cookie, res.Token, true))
+		w.Header().Set(s.csrfHeader(), csrf)
+
+		return next(ctx, w, response)
+	}
+}
+
+// End wraps the encoder of the logout route: the cookies are cleared whatever
+// the answer of the token service.
+func (s BrowserSession) End(next httptransport.EncodeResponseFunc) httptransport.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response any) error {
+		expired := s.cookie(s.Cookie, "", true)
+		expired.MaxAge = -1
+		http.SetCookie(w, expired)
+
+		if s.csrfMode() == CSRFDoubleSubmit {
+			expired = s.cookie(s.csrfCookie(), "", false)
+			expired.MaxAge = -1
+			http.SetCookie(w, expired)
+		}
+
+		return next(ctx, w, response)
+	}
+}
+
+// Token reads the session cookie like CookieToken. Requests with other
+// methods than GET, HEAD and OPTIONS must also carry the CSRF token in
+// CSRFHeader, or they are refused with 403.
+func (s BrowserSession) Token() TokenExtractor {
+	cookieToken := CookieToken(s.Cookie)
+
+	return func(r *http.Request) (string, error) {
+		token, err := cookieToken(r)
+		if err != nil || token == "" {
+			return token, err
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return token, nil
+		}
+
+		expected := s.synchronizerToken(token)
+		if s.csrfMode() == CSRFDoubleSubmit {
+			cookie, err := r.Cookie(s.csrfCookie())
+			if err != nil {
+				return "", &RequestError{Err: errCSRF, Status: http.StatusForbidden}
+			}
+
+			expected = cookie.Value
+		}
+
+		header := r.Header.Get(s.csrfHeader())
+		if header == "" || subtle.ConstantTimeCompare([]byte(header), []byte(expected)) != 1 {
+			return "", &RequestError{Err: errCSRF, Status: http.StatusForbidden}
+		}
+
+		return token, nil
+	}
+}
+
+func (s BrowserSession) cookie(name, value string, httpOnly bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Domain:   s.Domain,
+		Path:     s.Path,
+		MaxAge:   int(s.MaxAge.Seconds()),
+		Secure:   !s.Insecure,
+		HttpOnly: httpOnly,
+		SameSite: s.SameSite,
+	}
+
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+
+	if cookie.SameSite == 0 {
+		cookie.SameSite = http.SameSiteLaxMode
+	}
+
+	return cookie
+}
+
+func (s BrowserSession) synchronizerToken(token string) string {
+	mac := hmac.New(sha256.New, s.CSRFKey)
+	mac.Write([]byte(token))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s BrowserSession) csrfMode() string {
+	if s.CSRF == "" {
+		return CSRFDoubleSubmit
+	}
+
+	return s.CSRF
+}
+
+func (s BrowserSession) csrfCookie() string {
+	if s.CSRFCookie == "" {
+		return "csrf_token"
+	}
+
+	return s.CSRFCookie
+}
+
+func (s BrowserSession) csrfHeader() string {
+	if s.CSRFHeader == "" {
+		return "X-CSRF-Token"
+	}
+
+	return s.CSRFHeader
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// failed reports whether response is a go-kit Failer that failed.
+func failed(response any) (check bool) {
+	failer, ok := response.(kitendpoint.Failer)
+
+	return ok && failer.Failed() != nil
+}
@@ -0,0 +1,205 @@
+package transport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/service"
+	"app/internal/transport"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrowserSessionStart(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name       string
+		inCSRF     string
+		in         entity.TokenErrorResponse
+		outCookies []string
+	}{
+		{
+			name:       "DoubleSubmit",
+			in:         entity.TokenErrorResponse{Token: mock.TokenTest},
+			outCookies: []string{"csrf_token", "session"},
+		},
+		{
+			name:       "Synchronizer",
+			inCSRF:     transport.CSRFSynchronizer,
+			in:         entity.TokenErrorResponse{Token: mock.TokenTest},
+			outCookies: []string{"session"},
+		},
+		{
+			name: "ErrorSignIn",
+			in: entity.TokenErrorResponse{
+				Err:     service.ErrCredentials.Error(),
+				Failure: entity.Failure{Cause: service.ErrCredentials},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			session := transport.BrowserSession{
+				Cookie:  "session",
+				MaxAge:  time.Hour,
+				CSRF:    tt.inCSRF,
+				CSRFKey: []byte(mock.SecretTest),
+			}
+
+			w := httptest.NewRecorder()
+			assert.Nil(t, session.Start(transport.EncodeResponse)(context.TODO(), w, tt.in))
+
+			cookies := w.Result().Cookies()
+			names := make([]string, 0, len(cookies))
+
+			for _, cookie := range cookies {
+				names = append(names, cookie.Name)
+
+				assert.True(t, cookie.Secure)
+				assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+				assert.Equal(t, 3600, cookie.MaxAge)
+				assert.Equal(t, cookie.Name == "session", cookie.HttpOnly)
+			}
+
+			assert.ElementsMatch(t, tt.outCookies, names)
+
+			if len(tt.outCookies) == 0 {
+				assert.Empty(t, w.Header().Get("X-CSRF-Token"))
+			} else {
+				assert.NotEmpty(t, w.Header().Get("X-CSRF-Token"))
+			}
+		})
+	}
+}
+
+func TestBrowserSessionEnd(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	session := transport.BrowserSession{Cookie: "session"}
+	assert.Nil(t, session.End(transport.EncodeResponse)(context.TODO(), w, entity.ErrorResponse{}))
+
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 2)
+
+	for _, cookie := range cookies {
+		assert.Empty(t, cookie.Value)
+		assert.Equal(t, -1, cookie.MaxAge)
+	}
+}
+
+func TestBrowserSessionToken(t *testing.T) {
+	t.Parallel()
+
+	synchronizer := transport.BrowserSession{
+		Cookie:  "session",
+		CSRF:    transport.CSRFSynchronizer,
+		CSRFKey: []byte(mock.SecretTest),
+	}
+
+	w := httptest.NewRecorder()
+	assert.Nil(t, synchronizer.Start(transport.EncodeResponse)(
+		context.TODO(),
+		w,
+		entity.TokenErrorResponse{Token: mock.TokenTest},
+	))
+
+	synchronizerToken := w.Header().Get("X-CSRF-Token")
+
+	for _, tt := range []struct {
+		session    transport.BrowserSession
+		name       string
+		inMethod   string
+		inCSRF     string
+		inCookie   string
+		outToken   string
+		outErr     string
+		outStatus  int
+		withCookie bool
+	}{
+		{
+			name:     "SafeMethod",
+			session:  transport.BrowserSession{Cookie: "session"},
+			inMethod: http.MethodGet,
+			outToken: mock.TokenTest,
+		},
+		{
+			name:       "DoubleSubmit",
+			session:    transport.BrowserSession{Cookie: "session"},
+			inMethod:   http.MethodDelete,
+			inCSRF:     "csrf",
+			inCookie:   "csrf",
+			withCookie: true,
+			outToken:   mock.TokenTest,
+		},
+		{
+			name:     "Synchronizer",
+			session:  synchronizer,
+			inMethod: http.MethodPost,
+			inCSRF:   synchronizerToken,
+			outToken: mock.TokenTest,
+		},
+		{
+			name:      "ErrorDoubleSubmitWithoutCookie",
+			session:   transport.BrowserSession{Cookie: "session"},
+			inMethod:  http.MethodDelete,
+			inCSRF:    "csrf",
+			outErr:    "missing or invalid CSRF token",
+			outStatus: http.StatusForbidden,
+		},
+		{
+			name:       "ErrorDoubleSubmitMismatch",
+			session:    transport.BrowserSession{Cookie: "session"},
+			inMethod:   http.MethodPut,
+			inCSRF:     "csrf",
+			inCookie:   "other",
+			withCookie: true,
+			outErr:     "missing or invalid CSRF token",
+			outStatus:  http.StatusForbidden,
+		},
+		{
+			name:      "ErrorSynchronizerMissing",
+			session:   synchronizer,
+			inMethod:  http.MethodPost,
+			outErr:    "missing or invalid CSRF token",
+			outStatus: http.StatusForbidden,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(tt.inMethod, "/logout", nil)
+			req.AddCookie(&http.Cookie{Name: "session", Value: mock.TokenTest})
+			req.Header.Set("X-CSRF-Token", tt.inCSRF)
+
+			if tt.withCookie {
+				req.AddCookie(&http.Cookie{Name: "csrf_token", Value: tt.inCookie})
+			}
+
+			ctx := transport.ExtractToken(transport.BearerToken(), tt.session.Token())(context.TODO(), req)
+
+			r, err := transport.DecodeRequestWithHeader(entity.Token{})(ctx, req)
+			if tt.outErr == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, entity.Token{Token: tt.outToken}, r)
+
+				return
+			}
+
+			assert.EqualError(t, err, tt.outErr)
+
+			w := httptest.NewRecorder()
+			transport.EncodeError(ctx, err, w)
+			assert.Equal(t, tt.outStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"app/internal/service"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+var errMalformedToken = errors.New("malformed token")
+
+// b64token is the token syntax of RFC 6750, which JWTs and opaque tokens
+// both follow.
+var b64token = regexp.MustCompile(`^[A-Za-z0-9\-._~+/]+=*$`)
+
+// TokenExtractor reads the token of a request from one place, "" when the
+// request carries none there. Its errors are answered with 401, unless they
+// are a RequestError.
+type TokenExtractor func(r *http.Request) (token string, err error)
+
+type tokenContextKey struct{}
+
+// TokenError is a missing or malformed token. It is answered with 401 and a
+// WWW-Authenticate challenge (RFC 6750).
+type TokenError struct {
+	Err error
+}
+
+func (e *TokenError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode implements the go-kit StatusCoder.
+func (e *TokenError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+// Headers implements the go-kit Headerer.
+func (e *TokenError) Headers() http.Header {
+	return http.Header{"Www-Authenticate": []string{challenge(e)}}
+}
+
+// MarshalJSON implements json.Marshaler, see RequestError.
+func (e *TokenError) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		Err string `json:"err"`
+	}{Err: e.Err.Error()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode error: %w", err)
+	}
+
+	return data, nil
+}
+
+// BearerToken reads "Authorization: Bearer <token>". A bare token without
+// scheme, which the first clients send, is accepted too.
+func BearerToken() TokenExtractor {
+	return func(r *http.Request) (string, error) {
+		value := r.Header.Get("Authorization")
+		if value == "" {
+			return "", nil
+		}
+
+		scheme, token, found := strings.Cut(value, " ")
+		if !found {
+			return checkToken(value, "Authorization header")
+		}
+
+		if !strings.EqualFold(scheme, "Bearer") {
+			return "", fmt.Errorf("%w: Authorization scheme must be Bearer", errMalformedToken)
+		}
+
+		return checkToken(strings.TrimSpace(token), "Authorization header")
+	}
+}
+
+// HeaderToken reads the token from the header name.
+func HeaderToken(name string) TokenExtractor {
+	return func(r *http.Request) (string, error) {
+		if r.Header.Get(name) == "" {
+			return "", nil
+		}
+
+		return checkToken(r.Header.Get(name), name+" header")
+	}
+}
+
+// CookieToken reads the token from the cookie name.
+func CookieToken(name string) TokenExtractor {
+	return func(r *http.Request) (string, error) {
+		cookie, err := r.Cookie(name)
+		if err != nil || cookie.Value == "" {
+			return "", nil //nolint:nilerr
+		}
+
+		return checkToken(cookie.Value, name+" cookie")
+	}
+}
+
+// QueryToken reads the token from the query parameter name. Query strings
+// end up in logs and browser history, so it is only meant for clients that
+// cannot set headers.
+func QueryToken(name string) TokenExtractor {
+	return func(r *http.Request) (string, error) {
+		if r.URL.Query().Get(name) == "" {
+			return "", nil
+		}
+
+		return checkToken(r.URL.Query().Get(name), name+" query parameter")
+	}
+}
+
+// ExtractToken is a go-kit ServerBefore that makes the decoders of this
+// package take the token from the first of extractors that finds one. Without
+// it they only read BearerToken.
+func ExtractToken(extractors ...TokenExtractor) httptransport.RequestFunc {
+	return func(ctx context.Context, _ *http.Request) context.Context {
+		return context.WithValue(ctx, tokenContextKey{}, extractors)
+	}
+}
+
+// requestToken runs the extractors set by ExtractToken on r.
+func requestToken(ctx context.Context, r *http.Request) (token string, err error) {
+	extractors, ok := ctx.Value(tokenContextKey{}).([]TokenExtractor)
+	if !ok {
+		extractors = []TokenExtractor{BearerToken()}
+	}
+
+	for _, extract := range extractors {
+		var requestErr *RequestError
+
+		token, err = extract(r)
+
+		switch {
+		case errors.As(err, &requestErr):
+			return "", err
+		case err != nil:
+			return "", &TokenError{Err: err}
+		case token != "":
+			return token, nil
+		}
+	}
+
+	return "", &TokenError{Err: errFailedGetHeader}
+}
+
+func checkToken(token, source string) (string, error) {
+	if !b64token.MatchString(token) {
+		return "", fmt.Errorf("%w: invalid characters in the %s", errMalformedToken, source)
+	}
+
+	return token, nil
+}
+
+// challenge is the WWW-Authenticate value of the 401 caused by err, "" when
+// err is not about the token.
+func challenge(err error) string {
+	switch {
+	case errors.Is(err, errMalformedToken):
+		return `Bearer error="invalid_request", error_description="malformed token"`
+	case errors.Is(err, service.ErrTokenNotValid):
+		return `Bearer error="invalid_token", error_description="invalid token"`
+	case errors.Is(err, errFailedGetHeader):
+		return "Bearer"
+	default:
+		return ""
+	}
+}
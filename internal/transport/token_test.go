@@ -0,0 +1,150 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/transport"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractToken(t *testing.T) {
+	t.Parallel()
+
+	extractors := []transport.TokenExtractor{
+		transport.BearerToken(),
+		transport.HeaderToken("X-Auth-Token"),
+		transport.CookieToken("session"),
+		transport.QueryToken("access_token"),
+	}
+
+	for _, tt := range []struct {
+		name           string
+		inAuth         string
+		inHeader       string
+		inCookie       string
+		inQuery        string
+		outToken       string
+		outErr         string
+		outChallenge   string
+		withExtractors bool
+	}{
+		{
+			name:     "Bearer",
+			inAuth:   "Bearer " + mock.TokenTest,
+			outToken: mock.TokenTest,
+		},
+		{
+			name:     "BearerLowerCase",
+			inAuth:   "bearer " + mock.TokenTest,
+			outToken: mock.TokenTest,
+		},
+		{
+			name:     "Bare",
+			inAuth:   mock.TokenTest,
+			outToken: mock.TokenTest,
+		},
+		{
+			name:           "Header",
+			inHeader:       mock.TokenTest,
+			outToken:       mock.TokenTest,
+			withExtractors: true,
+		},
+		{
+			name:           "Cookie",
+			inCookie:       mock.TokenTest,
+			outToken:       mock.TokenTest,
+			withExtractors: true,
+		},
+		{
+			name:           "Query",
+			inQuery:        mock.TokenTest,
+			outToken:       mock.TokenTest,
+			withExtractors: true,
+		},
+		{
+			name:           "BearerFirst",
+			inAuth:         "Bearer " + mock.TokenTest,
+			inCookie:       "other",
+			outToken:       mock.TokenTest,
+			withExtractors: true,
+		},
+		{
+			name:         "ErrorCookieNotConfigured",
+			inCookie:     mock.TokenTest,
+			outErr:       "failed to get header",
+			outChallenge: "Bearer",
+		},
+		{
+			name:         "ErrorMissing",
+			outErr:       "failed to get header",
+			outChallenge: "Bearer",
+		},
+		{
+			name:         "ErrorScheme",
+			inAuth:       "Basic dXNlcm5hbWU6cGFzc3dvcmQ=",
+			outErr:       "malformed token: Authorization scheme must be Bearer",
+			outChallenge: `Bearer error="invalid_request", error_description="malformed token"`,
+		},
+		{
+			name:         "ErrorEmptyBearer",
+			inAuth:       "Bearer ",
+			outErr:       "malformed token: invalid characters in the Authorization header",
+			outChallenge: `Bearer error="invalid_request", error_description="malformed token"`,
+		},
+		{
+			name:           "ErrorCharacters",
+			inHeader:       "tok\"en",
+			outErr:         "malformed token: invalid characters in the X-Auth-Token header",
+			outChallenge:   `Bearer error="invalid_request", error_description="malformed token"`,
+			withExtractors: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/logout?access_token="+tt.inQuery, nil)
+			req.Header.Set("Authorization", tt.inAuth)
+			req.Header.Set("X-Auth-Token", tt.inHeader)
+			req.AddCookie(&http.Cookie{Name: "session", Value: tt.inCookie})
+
+			ctx := context.TODO()
+			if tt.withExtractors {
+				ctx = transport.ExtractToken(extractors...)(ctx, req)
+			}
+
+			r, err := transport.DecodeRequestWithHeader(entity.Token{})(ctx, req)
+			if tt.outErr == "" {
+				assert.Nil(t, err)
+				assert.Equal(t, entity.Token{Token: tt.outToken}, r)
+
+				return
+			}
+
+			assert.EqualError(t, err, tt.outErr)
+
+			w := httptest.NewRecorder()
+			httptransport.DefaultErrorEncoder(ctx, err, w)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.outChallenge, w.Header().Get("WWW-Authenticate"))
+
+			w = httptest.NewRecorder()
+			transport.EncodeError(transport.ForceProblems(ctx, req), err, w)
+
+			var problem entity.Problem
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.outChallenge, w.Header().Get("WWW-Authenticate"))
+			assert.Nil(t, json.NewDecoder(w.Body).Decode(&problem))
+			assert.Contains(t, problem.Type, "-token")
+		})
+	}
+}
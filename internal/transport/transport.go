@@ -6,13 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"app/internal/entity"
+	"app/internal/importer"
 
 	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/gorilla/mux"
 )
 
-var errFailedGetHeader = errors.New("failed to get header")
+var (
+	errFailedGetHeader = errors.New("failed to get header")
+	errFailedGetID     = errors.New("failed to get id")
+	errFailedGetQuery  = errors.New("failed to get query")
+)
 
 // DecodeRequestWithoutBody ...
 func DecodeRequestWithoutBody() httptransport.DecodeRequestFunc {
@@ -23,33 +30,206 @@ func DecodeRequestWithoutBody() httptransport.DecodeRequestFunc {
 	}
 }
 
-// DecodeRequest ...
+// DecodeRequestWithBody decodes the JSON body strictly, see decodeJSON. A
+// maxBodyBytes of 0 means DefaultMaxBodyBytes.
 func DecodeRequestWithBody[req entity.UsernamePasswordEmailRequest |
-	entity.UsernamePasswordRequest](request req,
+	entity.UsernamePasswordRequest](request req, maxBodyBytes int64,
 ) httptransport.DecodeRequestFunc {
 	return func(_ context.Context, r *http.Request) (any, error) {
-		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-			return nil, fmt.Errorf("failed to decode request: %w", err)
+		if err := decodeJSON(r, &request, maxBodyBytes); err != nil {
+			return nil, err
 		}
 
 		return request, nil
 	}
 }
 
+// DecodeRequestWithHeader reads the token, see ExtractToken.
 func DecodeRequestWithHeader(request entity.Token) httptransport.DecodeRequestFunc {
-	return func(_ context.Context, r *http.Request) (any, error) {
-		if r.Header.Get("Authorization") == "" {
-			return nil, errFailedGetHeader
+	return func(ctx context.Context, r *http.Request) (any, error) {
+		var err error
+
+		if request.Token, err = requestToken(ctx, r); err != nil {
+			return nil, err
+		}
+
+		return request, nil
+	}
+}
+
+// DecodeChangePasswordRequest reads the token like DecodeRequestWithHeader
+// and the passwords from the body like DecodeRequestWithBody.
+func DecodeChangePasswordRequest(
+	request entity.ChangePasswordRequest,
+	maxBodyBytes int64,
+) httptransport.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (any, error) {
+		token, err := requestToken(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = decodeJSON(r, &request, maxBodyBytes); err != nil {
+			return nil, err
+		}
+
+		request.Token = token
+
+		return request, nil
+	}
+}
+
+// DecodeRequestWithHeaderAndID reads the token like DecodeRequestWithHeader
+// and the user ID from the "id" route variable.
+func DecodeRequestWithHeaderAndID(request entity.TokenIDRequest) httptransport.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (any, error) {
+		token, err := requestToken(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errFailedGetID, err.Error())
+		}
+
+		request.Token = token
+		request.ID = id
+
+		return request, nil
+	}
+}
+
+// DecodeSessionRequest reads the token like DecodeRequestWithHeader and the
+// session ID from the "id" route variable.
+func DecodeSessionRequest(request entity.TokenSessionRequest) httptransport.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (any, error) {
+		var err error
+
+		if request.Token, err = requestToken(ctx, r); err != nil {
+			return nil, err
 		}
 
-		request.Token = r.Header.Get("Authorization")
+		request.ID = mux.Vars(r)["id"]
 
 		return request, nil
 	}
 }
 
-// EncodeResponse ...
-func EncodeResponse(_ context.Context, w http.ResponseWriter, response any) (err error) {
+// DecodeListUsersRequest reads the token like DecodeRequestWithHeader and
+// the list options from the query string.
+func DecodeListUsersRequest(request entity.ListUsersRequest) httptransport.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (any, error) {
+		var err error
+
+		if request.Token, err = requestToken(ctx, r); err != nil {
+			return nil, err
+		}
+
+		query := r.URL.Query()
+
+		for name, value := range map[string]*int{
+			"limit":  &request.Limit,
+			"offset": &request.Offset,
+		} {
+			if query.Get(name) == "" {
+				continue
+			}
+
+			if *value, err = strconv.Atoi(query.Get(name)); err != nil {
+				return nil, fmt.Errorf("%w: %s: %s", errFailedGetQuery, name, err.Error())
+			}
+		}
+
+		request.Cursor = query.Get("cursor")
+		request.UsernamePrefix = query.Get("usernamePrefix")
+		request.EmailDomain = query.Get("emailDomain")
+		request.Sort = query.Get("sort")
+
+		return request, nil
+	}
+}
+
+// DecodeImportUsersRequest reads the options from the query. The rows are
+// read from the body by Parse, in the format given by the Content-Type
+// header, and the body is bounded by maxBodyBytes, 0 means
+// DefaultMaxBodyBytes.
+func DecodeImportUsersRequest(request entity.ImportUsersRequest, maxBodyBytes int64) httptransport.DecodeRequestFunc {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	return func(ctx context.Context, r *http.Request) (any, error) {
+		var err error
+
+		if request.Token, err = requestToken(ctx, r); err != nil {
+			return nil, err
+		}
+
+		query := r.URL.Query()
+
+		if query.Get("concurrency") != "" {
+			if request.Concurrency, err = strconv.Atoi(query.Get("concurrency")); err != nil {
+				return nil, fmt.Errorf("%w: concurrency: %s", errFailedGetQuery, err.Error())
+			}
+		}
+
+		if query.Get("dryRun") != "" {
+			if request.DryRun, err = strconv.ParseBool(query.Get("dryRun")); err != nil {
+				return nil, fmt.Errorf("%w: dryRun: %s", errFailedGetQuery, err.Error())
+			}
+		}
+
+		format, err := importer.FormatFromContentType(r.Header.Get("Content-Type"))
+		if err != nil {
+			return nil, &RequestError{
+				Err:    fmt.Errorf("%w: %s", errUnsupportedMediaType, err.Error()),
+				Status: http.StatusUnsupportedMediaType,
+			}
+		}
+
+		request.Parse = func() (rows []entity.ImportRow, err error) {
+			if rows, err = importer.Parse(format, http.MaxBytesReader(nil, r.Body, maxBodyBytes)); err != nil {
+				return nil, importError(err, maxBodyBytes)
+			}
+
+			return rows, nil
+		}
+
+		return request, nil
+	}
+}
+
+// importError turns the errors of importer.Parse into request errors.
+func importError(err error, maxBodyBytes int64) *RequestError {
+	var tooLarge *http.MaxBytesError
+
+	if errors.As(err, &tooLarge) {
+		return &RequestError{
+			Err:    fmt.Errorf("%w: limit is %d bytes", errBodyTooLarge, maxBodyBytes),
+			Status: http.StatusRequestEntityTooLarge,
+		}
+	}
+
+	return &RequestError{
+		Err:    fmt.Errorf("%w: %s", errMalformedBody, err.Error()),
+		Status: http.StatusBadRequest,
+	}
+}
+
+// EncodeResponse writes response as JSON, with its status code when it is a
+// go-kit StatusCoder. Failed responses are written as problem details when
+// NegotiateErrorFormat asked for them.
+func EncodeResponse(ctx context.Context, w http.ResponseWriter, response any) (err error) {
+	if handled, err := encodeFailure(ctx, w, response); handled {
+		return err
+	}
+
+	if coder, ok := response.(httptransport.StatusCoder); ok {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(coder.StatusCode())
+	}
+
 	if err = json.NewEncoder(w).Encode(response); err != nil {
 		return fmt.Errorf("failed to encode response: %w", err)
 	}
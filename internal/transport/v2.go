@@ -0,0 +1,61 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+
+	"app/internal/entity"
+)
+
+// Bodies of the /v2 responses, errors are always problem details so none of
+// them has an err field, and users never carry their password hash.
+type (
+	tokenV2 struct {
+		Token string `json:"token"`
+	}
+
+	usersV2 struct {
+		Total      *int           `json:"total,omitempty"`
+		NextCursor string         `json:"nextCursor,omitempty"`
+		Users      []exportedUser `json:"users"`
+	}
+)
+
+// ForceProblems is a go-kit ServerBefore that makes the encoders of this
+// package write every error as problem details, whatever the Accept header.
+func ForceProblems(ctx context.Context, _ *http.Request) context.Context {
+	return context.WithValue(ctx, problemContextKey{}, true)
+}
+
+// EncodeResponseV2 writes the /v2 form of the responses of the endpoints:
+// failures as problem details, responses without a body as 204 No Content
+// and the others as their v2 DTO.
+func EncodeResponseV2(ctx context.Context, w http.ResponseWriter, response any) (err error) {
+	ctx = ForceProblems(ctx, nil)
+
+	if handled, err := encodeFailure(ctx, w, response); handled {
+		return err
+	}
+
+	switch res := response.(type) {
+	case entity.ErrorResponse:
+		w.WriteHeader(http.StatusNoContent)
+
+		return nil
+	case entity.TokenErrorResponse:
+		return EncodeResponse(ctx, w, tokenV2{Token: res.Token})
+	case entity.UserErrorResponse:
+		return EncodeResponse(ctx, w, redactUser(res.User))
+	case entity.UsersErrorResponse:
+		users := usersV2{Total: res.Total, NextCursor: res.NextCursor, Users: make([]exportedUser, len(res.Users))}
+		for i, user := range res.Users {
+			users.Users[i] = redactUser(user)
+		}
+
+		return EncodeResponse(ctx, w, users)
+	case entity.ImportReportErrorResponse:
+		return EncodeResponse(ctx, w, res.Report)
+	default:
+		return EncodeResponse(ctx, w, response)
+	}
+}
@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// scripts a username may be written in, each entry is one script as far as
+// mixing goes, so Japanese can use kanji and kana together. Letters of every
+// other script are grouped as one more.
+//
+//nolint:gochecknoglobals
+var scripts = [][]*unicode.RangeTable{
+	{unicode.Latin},
+	{unicode.Cyrillic},
+	{unicode.Greek},
+	{unicode.Armenian},
+	{unicode.Arabic},
+	{unicode.Hebrew},
+	{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul},
+}
+
+// prototypes maps characters to the ASCII letter they are confused with, a
+// subset of the Unicode confusables (UTS #39) covering Cyrillic, Greek and
+// digits.
+//
+//nolint:gochecknoglobals
+var prototypes = map[rune]rune{
+	'0': 'o', '1': 'l', '3': 'e', '5': 's',
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'і': 'i', 'ї': 'i',
+	'ј': 'j', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p', 'с': 'c',
+	'т': 't', 'у': 'y', 'х': 'x', 'ѕ': 's', 'һ': 'h', 'ԁ': 'd', 'ԛ': 'q',
+	'ԝ': 'w', 'ӏ': 'l',
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'μ': 'u',
+	'ν': 'v', 'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x', 'ω': 'w',
+	'ı': 'i', 'ɡ': 'g', 'ɑ': 'a',
+}
+
+// skeleton folds s so that confusable strings share it: lowercase, each
+// character replaced by its prototype and separators dropped.
+func skeleton(s string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(s) {
+		if strings.ContainsRune("._-", r) {
+			continue
+		}
+
+		if prototype, ok := prototypes[r]; ok {
+			r = prototype
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// mixedScripts reports whether the letters of s belong to more than one
+// script.
+func mixedScripts(s string) (check bool) {
+	first := -1
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		script := len(scripts)
+
+		for i, tables := range scripts {
+			if unicode.In(r, tables...) {
+				script = i
+
+				break
+			}
+		}
+
+		if first == -1 {
+			first = script
+		}
+
+		if script != first {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,229 @@
+package validation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"app/internal/entity"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Bounds of usernames and emails, the email ones come from RFC 5321.
+const (
+	MinUsernameLength int = 3
+	MaxUsernameLength int = 32
+	maxEmailLength    int = 254
+	maxLocalLength    int = 64
+)
+
+var ErrInvalidRequest = errors.New("invalid request")
+
+// Errors lists the invalid fields of a request.
+type Errors []entity.FieldError
+
+// Validator normalizes the usernames and emails of requests to NFKC and
+// checks them, see Username and Email.
+type Validator struct {
+	reserved   map[string]struct{}
+	disposable map[string]struct{}
+}
+
+// NewValidator rejects the reserved usernames, compared by their confusable
+// skeleton, and emails of the disposable domains or their subdomains.
+func NewValidator(reserved, disposable []string) *Validator {
+	v := &Validator{
+		reserved:   make(map[string]struct{}, len(reserved)),
+		disposable: make(map[string]struct{}, len(disposable)),
+	}
+
+	for _, name := range reserved {
+		v.reserved[skeleton(norm.NFKC.String(name))] = struct{}{}
+	}
+
+	for _, domain := range disposable {
+		v.disposable[strings.ToLower(strings.TrimSpace(domain))] = struct{}{}
+	}
+
+	return v
+}
+
+// DefaultReservedUsernames are names that could pass for the service itself.
+func DefaultReservedUsernames() []string {
+	return []string{
+		"abuse", "admin", "administrator", "api", "help", "hostmaster", "info",
+		"mail", "me", "null", "postmaster", "profile", "root", "security",
+		"signin", "signup", "staff", "support", "system", "undefined", "users",
+		"webmaster", "www",
+	}
+}
+
+// DefaultDisposableDomains are well known throwaway email providers.
+func DefaultDisposableDomains() []string {
+	return []string{
+		"10minutemail.com", "dispostable.com", "getnada.com", "guerrillamail.com",
+		"maildrop.cc", "mailinator.com", "sharklasers.com", "temp-mail.org",
+		"tempmail.com", "throwawaymail.com", "trashmail.com", "yopmail.com",
+	}
+}
+
+// ReadList reads one value per line, blank lines and lines starting with
+// "#" are skipped.
+func ReadList(path string) (values []string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read list: %w", err)
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		values = append(values, line)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read list: %w", err)
+	}
+
+	return values, nil
+}
+
+func (e Errors) Error() string {
+	fields := make([]string, len(e))
+	for i, field := range e {
+		fields[i] = field.Field + ": " + field.Message
+	}
+
+	return ErrInvalidRequest.Error() + ": " + strings.Join(fields, "; ")
+}
+
+func (e Errors) Is(target error) bool {
+	return target == ErrInvalidRequest //nolint:errorlint
+}
+
+// Validate returns request with its fields normalized, or Errors. Requests
+// of other types are returned as they are.
+func (v *Validator) Validate(request any) (normalized any, err error) {
+	var errs Errors
+
+	check := func(field, value string, validate func(string) (string, string)) string {
+		value, message := validate(value)
+		if message != "" {
+			errs = append(errs, entity.FieldError{Field: field, Message: message})
+		}
+
+		return value
+	}
+
+	switch req := request.(type) {
+	case entity.UsernamePasswordEmailRequest:
+		req.Username = check("username", req.Username, v.Username)
+		req.Password = check("password", req.Password, required)
+		req.Email = check("email", req.Email, v.Email)
+		normalized = req
+	case entity.UsernamePasswordRequest:
+		// Existing users may predate the rules, sign in only normalizes.
+		req.Username = check("username", norm.NFKC.String(req.Username), required)
+		req.Password = check("password", req.Password, required)
+		normalized = req
+	case entity.ChangePasswordRequest:
+		req.OldPassword = check("oldPassword", req.OldPassword, required)
+		req.NewPassword = check("newPassword", req.NewPassword, required)
+		normalized = req
+	default:
+		return request, nil
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return normalized, nil
+}
+
+// Username returns name in NFKC and a message when it is invalid: out of
+// bounds, with characters other than letters, digits, ".", "_" and "-",
+// mixing scripts, which is how most confusable names are made, or reserved.
+func (v *Validator) Username(name string) (normalized, message string) {
+	name = norm.NFKC.String(strings.TrimSpace(name))
+	length := utf8.RuneCountInString(name)
+
+	switch {
+	case length == 0:
+		return name, "is required"
+	case length < MinUsernameLength || length > MaxUsernameLength:
+		return name, fmt.Sprintf("must be %d to %d characters", MinUsernameLength, MaxUsernameLength)
+	}
+
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("._-", r) {
+			return name, fmt.Sprintf("must not contain %q", r)
+		}
+	}
+
+	if mixedScripts(name) {
+		return name, "must not mix letters of different scripts"
+	}
+
+	if _, ok := v.reserved[skeleton(name)]; ok {
+		return name, "is reserved"
+	}
+
+	return name, ""
+}
+
+// Email returns address in NFKC with its domain in lowercase and a message
+// when it is not a bare RFC 5322 address, has no dotted domain or belongs to
+// a disposable domain.
+func (v *Validator) Email(address string) (normalized, message string) {
+	address = norm.NFKC.String(strings.TrimSpace(address))
+	if address == "" {
+		return address, "is required"
+	}
+
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Name != "" || parsed.Address != address {
+		return address, "must be an email address"
+	}
+
+	at := strings.LastIndex(address, "@")
+	local, domain := address[:at], strings.ToLower(address[at+1:])
+
+	switch {
+	case len(address) > maxEmailLength || len(local) > maxLocalLength:
+		return address, "is too long"
+	case !strings.Contains(domain, ".") || strings.HasSuffix(domain, "."):
+		return address, "must have a domain"
+	}
+
+	for parent := domain; parent != ""; {
+		if _, ok := v.disposable[parent]; ok {
+			return address, "must not be a disposable address"
+		}
+
+		_, parent, _ = strings.Cut(parent, ".")
+	}
+
+	return local + "@" + domain, ""
+}
+
+func required(value string) (normalized, message string) {
+	if value == "" {
+		return value, "is required"
+	}
+
+	return value, ""
+}
@@ -0,0 +1,169 @@
+package validation_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"app/internal/entity"
+	"app/internal/entity/mock"
+	"app/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getValidatorTest() *validation.Validator {
+	return validation.NewValidator(
+		validation.DefaultReservedUsernames(),
+		validation.DefaultDisposableDomains(),
+	)
+}
+
+func TestUsername(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name       string
+		in         string
+		out        string
+		outMessage string
+	}{
+		{name: mock.NameNoError, in: mock.UsernameTest, out: mock.UsernameTest},
+		{name: "NoErrorNFKC", in: "ｃｅｓａｒ", out: "cesar"},
+		{name: "NoErrorCyrillic", in: "борис", out: "борис"},
+		{name: "NoErrorJapanese", in: "山田たろう", out: "山田たろう"},
+		{name: "ErrorEmpty", in: " ", outMessage: "is required"},
+		{name: "ErrorShort", in: "ab", out: "ab", outMessage: "must be 3 to 32 characters"},
+		{name: "ErrorCharacter", in: "user name", out: "user name", outMessage: `must not contain ' '`},
+		{name: "ErrorMixedScripts", in: "pаypal", out: "pаypal", outMessage: "must not mix letters of different scripts"},
+		{name: "ErrorReserved", in: "Admin", out: "Admin", outMessage: "is reserved"},
+		{name: "ErrorReservedConfusable", in: "r00t", out: "r00t", outMessage: "is reserved"},
+		{name: "ErrorReservedWholeScript", in: "ԝԝԝ", out: "ԝԝԝ", outMessage: "is reserved"},
+		{name: "ErrorReservedSeparators", in: "web.master", out: "web.master", outMessage: "is reserved"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, message := getValidatorTest().Username(tt.in)
+
+			assert.Equal(t, tt.out, out)
+			assert.Equal(t, tt.outMessage, message)
+		})
+	}
+}
+
+func TestEmail(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name       string
+		in         string
+		out        string
+		outMessage string
+	}{
+		{name: mock.NameNoError, in: mock.EmailTest, out: mock.EmailTest},
+		{name: "NoErrorDomainCase", in: "Cesar@Example.COM", out: "Cesar@example.com"},
+		{name: "NoErrorNFKC", in: "cesar＠example.com", out: "cesar@example.com"},
+		{name: "ErrorEmpty", outMessage: "is required"},
+		{name: "ErrorDisplayName", in: "Cesar <cesar@example.com>", out: "Cesar <cesar@example.com>", outMessage: "must be an email address"},
+		{name: "ErrorNoAt", in: "cesar", out: "cesar", outMessage: "must be an email address"},
+		{name: "ErrorDomain", in: "cesar@localhost", out: "cesar@localhost", outMessage: "must have a domain"},
+		{name: "ErrorDisposable", in: "cesar@mailinator.com", out: "cesar@mailinator.com", outMessage: "must not be a disposable address"},
+		{name: "ErrorDisposableSubdomain", in: "cesar@eu.yopmail.com", out: "cesar@eu.yopmail.com", outMessage: "must not be a disposable address"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, message := getValidatorTest().Email(tt.in)
+
+			assert.Equal(t, tt.out, out)
+			assert.Equal(t, tt.outMessage, message)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name      string
+		in        any
+		out       any
+		outFields []entity.FieldError
+	}{
+		{
+			name: mock.NameNoError,
+			in: entity.UsernamePasswordEmailRequest{
+				Username: "ｃｅｓａｒ",
+				Password: mock.PasswordTest,
+				Email:    mock.EmailTest,
+			},
+			out: entity.UsernamePasswordEmailRequest{
+				Username: "cesar",
+				Password: mock.PasswordTest,
+				Email:    mock.EmailTest,
+			},
+		},
+		{
+			name: mock.NameNoError + "SignInReserved",
+			in:   entity.UsernamePasswordRequest{Username: "admin", Password: mock.PasswordTest},
+			out:  entity.UsernamePasswordRequest{Username: "admin", Password: mock.PasswordTest},
+		},
+		{
+			name: mock.NameNoError + "OtherType",
+			in:   entity.Token{Token: mock.TokenTest},
+			out:  entity.Token{Token: mock.TokenTest},
+		},
+		{
+			name: "ErrorFields",
+			in:   entity.UsernamePasswordEmailRequest{Username: "admin", Email: "email"},
+			outFields: []entity.FieldError{
+				{Field: "username", Message: "is reserved"},
+				{Field: "password", Message: "is required"},
+				{Field: "email", Message: "must be an email address"},
+			},
+		},
+		{
+			name: "ErrorChangePassword",
+			in:   entity.ChangePasswordRequest{Token: mock.TokenTest, OldPassword: mock.PasswordTest},
+			outFields: []entity.FieldError{
+				{Field: "newPassword", Message: "is required"},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out, err := getValidatorTest().Validate(tt.in)
+			if tt.outFields == nil {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.out, out)
+
+				return
+			}
+
+			var errs validation.Errors
+
+			assert.ErrorIs(t, err, validation.ErrInvalidRequest)
+			assert.ErrorAs(t, err, &errs)
+			assert.Equal(t, tt.outFields, []entity.FieldError(errs))
+		})
+	}
+}
+
+func TestReadList(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "list.txt")
+	assert.Nil(t, os.WriteFile(path, []byte("# comment\nexample.com\n\n  example.org  \n"), 0o600))
+
+	values, err := validation.ReadList(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"example.com", "example.org"}, values)
+
+	_, err = validation.ReadList(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
@@ -1,11 +1,11 @@
 #!/bin/bash
 
 #SignUp
-# response=$(curl -X POST -k http://localhost:8080/signup -d '{"username":"cesar","password":"01234","email":"cfabrica46@gmail.com"}')
+# response=$(curl -X POST -k http://localhost:8080/signup -H "Content-Type: application/json" -d '{"username":"cesar","password":"correct-horse-42","email":"cfabrica46@gmail.com"}')
 
 #Signin
-response=$(curl -X POST -Lk http://localhost:8080/signin -d '{"username":"cesar","password":"01234"}')
-# curl -X POST -Lk http://localhost:8080/signin -d '{"username":"cesar","password":"01234"}'
+response=$(curl -X POST -Lk http://localhost:8080/signin -H "Content-Type: application/json" -d '{"username":"cesar","password":"correct-horse-42"}')
+# curl -X POST -Lk http://localhost:8080/signin -H "Content-Type: application/json" -d '{"username":"cesar","password":"correct-horse-42"}'
 
 # echo "$response"
 
@@ -14,10 +14,10 @@ token=$(echo "$response" | jq -r '.token')
 # echo "$token"
 
 #ShowUsers
-# curl -X GET -Lk http://localhost:8080/users
+# curl -X GET -Lk http://localhost:8080/users -H "Authorization: Bearer $token"
 
 #Profile
-curl -X POST -k http://localhost:8080/profile -H "Authorization: $token"
+curl -X POST -k http://localhost:8080/profile -H "Authorization: Bearer $token"
 
 #Delete
-# curl -X DELETE -Lk http://localhost:8080/profile -H "Authorization: $token"
+# curl -X DELETE -Lk http://localhost:8080/profile -H "Authorization: Bearer $token"